@@ -0,0 +1,58 @@
+package ossslim
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+type (
+	// UploadJob is a local file to be uploaded to Remote path by UploadFiles.
+	// If ContentType is empty, it is determined by the extension of
+	// LocalPath.
+	UploadJob struct {
+		LocalPath   string
+		Remote      string
+		ContentType string
+	}
+
+	// UploadResult is the result of an UploadJob. Request may be nil if the
+	// local file can not be read.
+	UploadResult struct {
+		UploadJob
+		Request *Request
+		Err     error
+	}
+)
+
+// UploadFiles uploads local files from jobs with at most concurrency files
+// at the same time and sends results to the returned channel, which is
+// closed after jobs is closed and all jobs are done. If concurrency is not
+// greater than 0, number of CPUs is used. Every job has a result, if ctx is
+// canceled, remaining jobs are not uploaded and their results have ctx.Err().
+// Options are applied to every upload request.
+func (c *Client) UploadFiles(ctx context.Context, jobs <-chan UploadJob, concurrency int, options ...Option) <-chan UploadResult {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	results := make(chan UploadResult)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				result := UploadResult{UploadJob: job}
+				if result.Err = ctx.Err(); result.Err == nil {
+					result.Request, result.Err = c.UploadFileWithContext(ctx, job.Remote, job.LocalPath, job.ContentType, options...)
+				}
+				results <- result
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
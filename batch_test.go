@@ -0,0 +1,41 @@
+package ossslim
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestUploadFiles(t *testing.T) {
+	var mu sync.Mutex
+	uploaded := map[string]string{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		uploaded[r.URL.Path] = r.Header.Get("Content-Type")
+		mu.Unlock()
+	})
+	jobs := make(chan UploadJob)
+	go func() {
+		defer close(jobs)
+		jobs <- UploadJob{LocalPath: "request.go", Remote: "a/request.go", ContentType: "text/plain"}
+		jobs <- UploadJob{LocalPath: "batch.go", Remote: "a/batch.go"}
+		jobs <- UploadJob{LocalPath: "not-exists.go", Remote: "a/not-exists.go"}
+	}()
+	var errs int
+	for result := range client.UploadFiles(context.Background(), jobs, 2) {
+		if result.Err != nil {
+			if result.Remote != "a/not-exists.go" {
+				t.Error("unexpected error", result.Err)
+			}
+			errs++
+		}
+	}
+	if errs != 1 {
+		t.Fatal("should have one error")
+	}
+	if len(uploaded) != 2 || uploaded["/a/request.go"] != "text/plain" ||
+		uploaded["/a/batch.go"] != "application/octet-stream" {
+		t.Fatal("wrong uploaded files", uploaded)
+	}
+}
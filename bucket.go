@@ -0,0 +1,205 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/url"
+)
+
+// Canned ACLs of buckets and files. ACLDefault can only be used for files,
+// which makes a file inherit the ACL of its bucket.
+const (
+	ACLDefault         = "default"
+	ACLPrivate         = "private"
+	ACLPublicRead      = "public-read"
+	ACLPublicReadWrite = "public-read-write"
+)
+
+type (
+	// BucketInfo contains metadata of a bucket. Location is the region like
+	// oss-cn-hangzhou. Versioning is "Enabled", "Suspended" or empty if
+	// versioning has never been enabled.
+	BucketInfo struct {
+		Name             string
+		Location         string
+		CreationDate     string
+		ExtranetEndpoint string
+		IntranetEndpoint string
+		StorageClass     string
+		Versioning       string
+		ACL              string `xml:"AccessControlList>Grant"`
+		Owner            Owner
+	}
+
+	bucketLocation struct {
+		XMLName  xml.Name `xml:"LocationConstraint"`
+		Location string   `xml:",chardata"`
+	}
+
+	bucketInfoResult struct {
+		Bucket BucketInfo
+	}
+
+	accessControlPolicy struct {
+		Grant string `xml:"AccessControlList>Grant"`
+	}
+)
+
+// BucketExists wraps BucketExistsWithContext using context.Background.
+func (c *Client) BucketExists() (bool, error) {
+	return c.BucketExistsWithContext(context.Background())
+}
+
+// BucketExistsWithContext returns true if the bucket exists. It returns
+// false if OSS responds with NoSuchBucket error. A WrongRegionError is
+// returned if the bucket is in another region.
+func (c *Client) BucketExistsWithContext(ctx context.Context) (bool, error) {
+	_, err := c.BucketLocationWithContext(ctx)
+	var ossErr *Error
+	if errors.As(err, &ossErr) && ossErr.Code == "NoSuchBucket" {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// BucketLocation wraps BucketLocationWithContext using context.Background.
+func (c *Client) BucketLocation() (string, error) {
+	return c.BucketLocationWithContext(context.Background())
+}
+
+// BucketLocationWithContext returns the region of the bucket, for example
+// oss-cn-hangzhou.
+func (c *Client) BucketLocationWithContext(ctx context.Context) (string, error) {
+	var response bytes.Buffer
+	req := &Request{
+		client:   c,
+		ctx:      ctx,
+		remote:   "/",
+		method:   "GET",
+		respBody: &response,
+		queries:  url.Values{"location": []string{""}},
+	}
+	if err := req.do(); err != nil {
+		return "", err
+	}
+	var location bucketLocation
+	if err := xml.NewDecoder(&response).Decode(&location); err != nil {
+		return "", err
+	}
+	return location.Location, nil
+}
+
+// GetBucketACL wraps GetBucketACLWithContext using context.Background.
+func (c *Client) GetBucketACL() (string, error) {
+	return c.GetBucketACLWithContext(context.Background())
+}
+
+// GetBucketACLWithContext returns the ACL of the bucket, which is one of
+// ACLPrivate, ACLPublicRead and ACLPublicReadWrite.
+func (c *Client) GetBucketACLWithContext(ctx context.Context) (string, error) {
+	var response bytes.Buffer
+	req := &Request{
+		client:   c,
+		ctx:      ctx,
+		remote:   "/",
+		method:   "GET",
+		respBody: &response,
+		queries:  url.Values{"acl": []string{""}},
+	}
+	if err := req.do(); err != nil {
+		return "", err
+	}
+	var policy accessControlPolicy
+	if err := xml.NewDecoder(&response).Decode(&policy); err != nil {
+		return "", err
+	}
+	return policy.Grant, nil
+}
+
+// SetBucketACL wraps SetBucketACLWithContext using context.Background.
+func (c *Client) SetBucketACL(acl string) error {
+	return c.SetBucketACLWithContext(context.Background(), acl)
+}
+
+// SetBucketACLWithContext changes the ACL of the bucket to acl, which should
+// be one of ACLPrivate, ACLPublicRead and ACLPublicReadWrite.
+func (c *Client) SetBucketACLWithContext(ctx context.Context, acl string) error {
+	req := &Request{
+		client:  c,
+		ctx:     ctx,
+		remote:  "/",
+		method:  "PUT",
+		queries: url.Values{"acl": []string{""}},
+	}
+	req.setHeader("x-oss-acl", acl)
+	return req.do()
+}
+
+// GetBucketInfo wraps GetBucketInfoWithContext using context.Background.
+func (c *Client) GetBucketInfo() (*BucketInfo, error) {
+	return c.GetBucketInfoWithContext(context.Background())
+}
+
+// GetBucketInfoWithContext returns metadata of the bucket, like its region,
+// creation date, storage class and versioning status.
+func (c *Client) GetBucketInfoWithContext(ctx context.Context) (*BucketInfo, error) {
+	var response bytes.Buffer
+	req := &Request{
+		client:   c,
+		ctx:      ctx,
+		remote:   "/",
+		method:   "GET",
+		respBody: &response,
+		queries:  url.Values{"bucketInfo": []string{""}},
+	}
+	if err := req.do(); err != nil {
+		return nil, err
+	}
+	var result bucketInfoResult
+	if err := xml.NewDecoder(&response).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result.Bucket, nil
+}
+
+// Ping wraps PingWithContext using context.Background.
+func (c *Client) Ping() error {
+	return c.PingWithContext(context.Background())
+}
+
+// PingWithContext lists at most one file in the bucket to check if OSS can be
+// reached and the credentials are valid. It returns an AuthError if OSS
+// rejects the credentials and a NetworkError if the request fails to be
+// sent, other errors (like WrongRegionError) are returned as is.
+func (c *Client) PingWithContext(ctx context.Context) error {
+	req := &Request{
+		client:   c,
+		ctx:      ctx,
+		remote:   "/",
+		method:   "GET",
+		respBody: ioutil.Discard,
+		queries:  url.Values{"max-keys": []string{"1"}},
+	}
+	err := req.do()
+	if err == nil {
+		return nil
+	}
+	var ossErr *Error
+	if errors.As(err, &ossErr) {
+		if ossErr.StatusCode == 401 || ossErr.StatusCode == 403 {
+			var wrongRegion *WrongRegionError
+			if errors.As(err, &wrongRegion) {
+				return err
+			}
+			return &AuthError{ossErr}
+		}
+		return err
+	}
+	if req.Response == nil {
+		return &NetworkError{err}
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package ossslim
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBucketLocation(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" || r.URL.RawQuery != "location" {
+			t.Error("wrong request", r.URL)
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<LocationConstraint>oss-cn-hangzhou</LocationConstraint>`))
+	})
+	location, err := client.BucketLocation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location != "oss-cn-hangzhou" {
+		t.Fatal("wrong location", location)
+	}
+	exists, err := client.BucketExists()
+	if err != nil || !exists {
+		t.Fatal("bucket should exist", err)
+	}
+}
+
+func TestBucketNotExists(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>NoSuchBucket</Code>
+  <Message>The specified bucket does not exist.</Message>
+  <BucketName>test</BucketName>
+</Error>`))
+	})
+	exists, err := client.BucketExists()
+	if err != nil || exists {
+		t.Fatal("bucket should not exist", err)
+	}
+}
+
+func TestBucketACL(t *testing.T) {
+	var acl string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" || r.URL.RawQuery != "acl" {
+			t.Error("wrong request", r.URL)
+		}
+		if r.Method == "PUT" {
+			acl = r.Header.Get("x-oss-acl")
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<AccessControlPolicy>
+  <Owner><ID>0022012****</ID><DisplayName>user_example</DisplayName></Owner>
+  <AccessControlList><Grant>` + acl + `</Grant></AccessControlList>
+</AccessControlPolicy>`))
+	})
+	if err := client.SetBucketACL(ACLPublicRead); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetBucketACL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != ACLPublicRead {
+		t.Error("wrong acl", got)
+	}
+	req := &Request{client: client, remote: "/", queries: url.Values{"acl": []string{""}}}
+	if res := req.canonicalizedResource(); res != "/test/?acl" {
+		t.Error("wrong canonicalized resource", res)
+	}
+}
+
+func TestBucketInfo(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" || r.URL.RawQuery != "bucketInfo" {
+			t.Error("wrong request", r.URL)
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<BucketInfo>
+  <Bucket>
+    <CreationDate>2013-07-31T10:56:21.000Z</CreationDate>
+    <ExtranetEndpoint>oss-cn-hangzhou.aliyuncs.com</ExtranetEndpoint>
+    <IntranetEndpoint>oss-cn-hangzhou-internal.aliyuncs.com</IntranetEndpoint>
+    <Location>oss-cn-hangzhou</Location>
+    <StorageClass>Standard</StorageClass>
+    <Versioning>Enabled</Versioning>
+    <Name>test</Name>
+    <Owner><DisplayName>username</DisplayName><ID>27183473914****</ID></Owner>
+    <AccessControlList><Grant>private</Grant></AccessControlList>
+  </Bucket>
+</BucketInfo>`))
+	})
+	info, err := client.GetBucketInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "test" || info.Location != "oss-cn-hangzhou" || info.StorageClass != "Standard" ||
+		info.Versioning != "Enabled" || info.ACL != ACLPrivate || info.Owner.ID != "27183473914****" ||
+		info.CreationDate != "2013-07-31T10:56:21.000Z" {
+		t.Errorf("wrong info %+v", info)
+	}
+}
+
+func TestPing(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("max-keys") != "1" {
+			t.Error("wrong query", r.URL.RawQuery)
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "OSS foo:") {
+			w.WriteHeader(403)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>InvalidAccessKeyId</Code>
+  <Message>The OSS Access Key Id you provided does not exist in our records.</Message>
+</Error>`))
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult></ListBucketResult>`))
+	})
+	if err := client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	client.AccessKeyId = "wrong"
+	var authErr *AuthError
+	if err := client.Ping(); !errors.As(err, &authErr) || authErr.Err.Code != "InvalidAccessKeyId" {
+		t.Fatal("should be auth error", err)
+	}
+	client.Prefix = "http://127.0.0.1:1"
+	var networkErr *NetworkError
+	if err := client.Ping(); !errors.As(err, &networkErr) {
+		t.Fatal("should be network error", err)
+	}
+}
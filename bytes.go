@@ -0,0 +1,70 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"io"
+)
+
+// maxSizeWriter returns ErrTooLarge if more than n bytes are written.
+type maxSizeWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *maxSizeWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > w.n {
+		return 0, ErrTooLarge
+	}
+	w.n -= int64(len(p))
+	return w.w.Write(p)
+}
+
+// WithMaxSize makes a download request fail with ErrTooLarge if the file is
+// larger than maxSize bytes.
+func WithMaxSize(maxSize int64) Option {
+	return func(req *Request) {
+		req.maxSize = maxSize
+	}
+}
+
+// DownloadBytes wraps DownloadBytesWithContext using context.Background.
+func (c *Client) DownloadBytes(remote string, options ...Option) ([]byte, *Request, error) {
+	return c.DownloadBytesWithContext(context.Background(), remote, options...)
+}
+
+// DownloadBytesWithContext downloads remote file into memory and returns its
+// content. Use the WithMaxSize option to avoid running out of memory if the
+// file may be unexpectedly large.
+func (c *Client) DownloadBytesWithContext(ctx context.Context, remote string, options ...Option) ([]byte, *Request, error) {
+	var buf bytes.Buffer
+	req, err := c.download(ctx, remote, &buf, false, options)
+	if err != nil {
+		return nil, req, err
+	}
+	return buf.Bytes(), req, nil
+}
+
+// UploadBytes wraps UploadBytesWithContext using context.Background.
+func (c *Client) UploadBytes(remote string, data []byte, contentType string, options ...Option) (*Request, error) {
+	return c.UploadBytesWithContext(context.Background(), remote, data, contentType, options...)
+}
+
+// UploadBytesWithContext uploads data to remote path with its MD5, so OSS
+// can verify the integrity of the uploaded file.
+func (c *Client) UploadBytesWithContext(ctx context.Context, remote string, data []byte, contentType string, options ...Option) (*Request, error) {
+	md5sum := md5.Sum(data)
+	return c.UploadWithContext(ctx, remote, bytes.NewReader(data), md5sum[:], contentType, options...)
+}
+
+// UploadString wraps UploadStringWithContext using context.Background.
+func (c *Client) UploadString(remote, data, contentType string, options ...Option) (*Request, error) {
+	return c.UploadStringWithContext(context.Background(), remote, data, contentType, options...)
+}
+
+// UploadStringWithContext is like UploadBytesWithContext but uploads a
+// string.
+func (c *Client) UploadStringWithContext(ctx context.Context, remote, data, contentType string, options ...Option) (*Request, error) {
+	return c.UploadBytesWithContext(ctx, remote, []byte(data), contentType, options...)
+}
@@ -0,0 +1,53 @@
+package ossslim
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestDownloadBytes(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// no content length
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte("hello world"))
+	})
+	content, _, err := client.DownloadBytes("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello world" {
+		t.Error("wrong content", string(content))
+	}
+	if _, _, err := client.DownloadBytes("foo", WithMaxSize(11)); err != nil {
+		t.Error(err)
+	}
+	for _, remote := range []string{"foo", "chunked"} {
+		if _, _, err := client.DownloadBytes(remote, WithMaxSize(10)); !errors.Is(err, ErrTooLarge) {
+			t.Error("wrong error", remote, err)
+		}
+	}
+}
+
+func TestUploadBytes(t *testing.T) {
+	var bodies, md5s []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		md5s = append(md5s, r.Header.Get("Content-MD5"))
+	})
+	if _, err := client.UploadBytes("foo", []byte("hello"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UploadString("foo", "hello", "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	expected := base64.StdEncoding.EncodeToString(md5sum([]byte("hello")))
+	if len(bodies) != 2 || bodies[0] != "hello" || bodies[1] != "hello" || md5s[0] != expected || md5s[1] != expected {
+		t.Error("wrong requests", bodies, md5s)
+	}
+}
@@ -0,0 +1,54 @@
+package ossslim
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Callback makes OSS send a POST request to URL after file is uploaded. Body
+// is the request body which can contain system variables like ${object},
+// ${size}, ${mimeType} and custom variables like ${x:foo} whose values are
+// set in Vars (keys must start with "x:"). BodyType defaults to
+// "application/x-www-form-urlencoded". Host defaults to the host of URL.
+// For more info, visit https://help.aliyun.com/document_detail/31989.html
+type Callback struct {
+	URL      string
+	Host     string
+	Body     string
+	BodyType string
+	Vars     map[string]string
+}
+
+// WithCallback sets the x-oss-callback (and x-oss-callback-var) header of an
+// upload request. If the callback fails, upload returns error and the file is
+// still uploaded.
+func WithCallback(callback Callback) Option {
+	return func(req *Request) {
+		req.setHeader("x-oss-callback", callback.encode())
+		if vars := callback.encodeVars(); vars != "" {
+			req.setHeader("x-oss-callback-var", vars)
+		}
+	}
+}
+
+func (callback Callback) encode() string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(struct {
+		URL      string `json:"callbackUrl"`
+		Host     string `json:"callbackHost,omitempty"`
+		Body     string `json:"callbackBody"`
+		BodyType string `json:"callbackBodyType,omitempty"`
+	}{callback.URL, callback.Host, callback.Body, callback.BodyType})
+	return base64.StdEncoding.EncodeToString(bytes.TrimSpace(buf.Bytes()))
+}
+
+func (callback Callback) encodeVars() string {
+	if len(callback.Vars) == 0 {
+		return ""
+	}
+	j, _ := json.Marshal(callback.Vars)
+	return base64.StdEncoding.EncodeToString(j)
+}
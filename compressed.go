@@ -0,0 +1,74 @@
+package ossslim
+
+import (
+	"bytes"
+	"strings"
+)
+
+// magic numbers of compressed formats at the beginning of files
+var compressedMagics = [][]byte{
+	{0x1f, 0x8b},                       // gzip
+	{'P', 'K', 0x03, 0x04},             // zip (also docx, jar, apk, etc.)
+	{'P', 'K', 0x05, 0x06},             // empty zip
+	{'B', 'Z', 'h'},                    // bzip2
+	{0xfd, '7', 'z', 'X', 'Z', 0x00},   // xz
+	{0x28, 0xb5, 0x2f, 0xfd},           // zstd
+	{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}, // 7z
+	{'R', 'a', 'r', '!', 0x1a, 0x07},   // rar
+	{0x89, 'P', 'N', 'G'},              // png
+	{0xff, 0xd8, 0xff},                 // jpeg
+	{'G', 'I', 'F', '8'},               // gif
+	{'w', 'O', 'F', 'F'},               // woff
+	{'w', 'O', 'F', '2'},               // woff2
+	{0x1a, 0x45, 0xdf, 0xa3},           // webm, mkv
+	{'O', 'g', 'g', 'S'},               // ogg
+	{'I', 'D', '3'},                    // mp3
+	{'f', 'L', 'a', 'C'},               // flac
+	{0x00, 0x00, 0x00, 0x0c, 'j', 'P'}, // jpeg 2000
+}
+
+// IsCompressed returns true if head, the first bytes (at least 12) of a
+// file, starts with the magic number of a compressed format, like gzip, zip,
+// PNG, JPEG, WebP, WOFF or MP4, so compressing it again (for example with
+// Content-Encoding: gzip) wastes CPU and hardly reduces or even increases
+// its size.
+func IsCompressed(head []byte) bool {
+	for _, magic := range compressedMagics {
+		if bytes.HasPrefix(head, magic) {
+			return true
+		}
+	}
+	if len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP" {
+		return true
+	}
+	if len(head) >= 8 && string(head[4:8]) == "ftyp" {
+		// mp4, mov, heic, avif, etc.
+		return true
+	}
+	return false
+}
+
+// IsCompressedContentType returns true if files of contentType are usually
+// compressed, like most images, audio, video, fonts and archives, see
+// IsCompressed. Parameters like "; charset=utf-8" are ignored.
+func IsCompressedContentType(contentType string) bool {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	switch contentType {
+	case "image/svg+xml", "image/x-icon", "image/vnd.microsoft.icon", "image/bmp",
+		"image/x-ms-bmp", "image/tiff", "audio/wav", "audio/x-wav", "audio/midi":
+		return false
+	case "application/zip", "application/gzip", "application/x-gzip",
+		"application/x-bzip2", "application/x-xz", "application/zstd",
+		"application/x-7z-compressed", "application/x-rar-compressed",
+		"application/vnd.rar", "application/java-archive",
+		"application/vnd.android.package-archive", "application/epub+zip",
+		"application/font-woff", "font/woff", "font/woff2":
+		return true
+	}
+	return strings.HasPrefix(contentType, "image/") ||
+		strings.HasPrefix(contentType, "audio/") ||
+		strings.HasPrefix(contentType, "video/")
+}
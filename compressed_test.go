@@ -0,0 +1,62 @@
+package ossslim
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func TestIsCompressed(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte("hello"))
+	gz.Close()
+	for _, head := range [][]byte{
+		gzipped.Bytes(),
+		pngData,
+		[]byte("PK\x03\x04\x14\x00\x00\x00"),
+		[]byte("\xff\xd8\xff\xe0\x00\x10JFIF"),
+		[]byte("GIF89a\x01\x00"),
+		[]byte("RIFF\x24\x00\x00\x00WEBPVP8 "),
+		[]byte("\x00\x00\x00\x20ftypisom"),
+		[]byte("wOF2\x00\x01\x00\x00"),
+	} {
+		if !IsCompressed(head) {
+			t.Errorf("%q should be compressed", head)
+		}
+	}
+	for _, head := range [][]byte{
+		nil,
+		[]byte("<!DOCTYPE html>"),
+		[]byte("body { color: red }"),
+		[]byte(`{"foo":"bar"}`),
+		[]byte("RIFF\x24\x00\x00\x00WAVEfmt "),
+		[]byte("\x1f"),
+	} {
+		if IsCompressed(head) {
+			t.Errorf("%q should not be compressed", head)
+		}
+	}
+}
+
+func TestIsCompressedContentType(t *testing.T) {
+	for contentType, expected := range map[string]bool{
+		"image/png":                       true,
+		"IMAGE/JPEG":                      true,
+		"video/mp4":                       true,
+		"application/zip":                 true,
+		"application/gzip":                true,
+		"font/woff2":                      true,
+		"image/svg+xml":                   false,
+		"image/x-icon":                    false,
+		"text/html; charset=utf-8":        false,
+		"application/javascript":          false,
+		"application/json":                false,
+		"application/octet-stream":        false,
+		"application/x-gzip; charset=foo": true,
+	} {
+		if IsCompressedContentType(contentType) != expected {
+			t.Error("wrong result for", contentType)
+		}
+	}
+}
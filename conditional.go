@@ -0,0 +1,58 @@
+package ossslim
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithIfModifiedSince makes a download request only return the file
+// if it has been modified after t. Otherwise no error is returned and
+// NotModified of the request is true.
+func WithIfModifiedSince(t time.Time) Option {
+	return func(req *Request) {
+		req.setHeader("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// WithIfUnmodifiedSince makes a download request only return the
+// file if it has not been modified after t. Otherwise the request fails with
+// an *Error of status code 412 and code PreconditionFailed.
+func WithIfUnmodifiedSince(t time.Time) Option {
+	return func(req *Request) {
+		req.setHeader("If-Unmodified-Since", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// WithCopySourceIfMatch makes Copy only copy the source file if its ETag is
+// etag. Otherwise the copy fails with ErrPreconditionFailed.
+func WithCopySourceIfMatch(etag string) Option {
+	return func(req *Request) {
+		req.setHeader("x-oss-copy-source-if-match", etag)
+	}
+}
+
+// WithCopySourceIfNoneMatch makes Copy only copy the source file if its
+// ETag is not etag. Otherwise the copy fails with ErrPreconditionFailed.
+func WithCopySourceIfNoneMatch(etag string) Option {
+	return func(req *Request) {
+		req.setHeader("x-oss-copy-source-if-none-match", etag)
+	}
+}
+
+// WithCopySourceIfModifiedSince makes Copy only copy the source file if it
+// has been modified after t. Otherwise the copy fails with
+// ErrPreconditionFailed.
+func WithCopySourceIfModifiedSince(t time.Time) Option {
+	return func(req *Request) {
+		req.setHeader("x-oss-copy-source-if-modified-since", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// WithCopySourceIfUnmodifiedSince makes Copy only copy the source file if
+// it has not been modified after t. Otherwise the copy fails with
+// ErrPreconditionFailed.
+func WithCopySourceIfUnmodifiedSince(t time.Time) Option {
+	return func(req *Request) {
+		req.setHeader("x-oss-copy-source-if-unmodified-since", t.UTC().Format(http.TimeFormat))
+	}
+}
@@ -0,0 +1,90 @@
+package ossslim
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIfModifiedSince(t *testing.T) {
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if since := r.Header.Get("If-Modified-Since"); since != "" {
+			if since != "Thu, 02 Jan 2020 03:04:05 GMT" {
+				t.Error("wrong If-Modified-Since", since)
+			}
+			w.WriteHeader(304)
+			return
+		}
+		if since := r.Header.Get("If-Unmodified-Since"); since != "" {
+			w.WriteHeader(412)
+			w.Write([]byte(`<Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold.</Message></Error>`))
+			return
+		}
+		w.Write([]byte("new"))
+	})
+	var buf bytes.Buffer
+	req, err := client.Download("foo", &buf, WithIfModifiedSince(modified))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !req.NotModified || buf.Len() != 0 {
+		t.Error("should not be modified", req.NotModified, buf.String())
+	}
+
+	localPath := filepath.Join(t.TempDir(), "foo")
+	if err := ioutil.WriteFile(localPath, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DownloadFile("foo", localPath, WithIfModifiedSince(modified)); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := ioutil.ReadFile(localPath); string(content) != "old" {
+		t.Error("file should not be replaced", string(content))
+	}
+
+	_, err = client.Download("foo", &buf, WithIfUnmodifiedSince(modified))
+	var ossErr *Error
+	if !errors.As(err, &ossErr) || ossErr.StatusCode != 412 {
+		t.Error("wrong error", err)
+	}
+}
+
+func TestConditionalCopy(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-oss-copy-source-if-match") == `"bar"` {
+			w.WriteHeader(412)
+			w.Write([]byte(`<Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold.</Message></Error>`))
+			return
+		}
+		if r.Header.Get("x-oss-copy-source-if-none-match") == `"foo"` {
+			w.WriteHeader(304)
+			return
+		}
+		if since := r.Header.Get("x-oss-copy-source-if-unmodified-since"); since != "Thu, 02 Jan 2020 03:04:05 GMT" {
+			t.Error("wrong x-oss-copy-source-if-unmodified-since", since)
+		}
+		w.Write([]byte(`<CopyObjectResult><ETag>"foo"</ETag></CopyObjectResult>`))
+	})
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	req, err := client.Copy("a", "b", WithCopySourceIfMatch(`"foo"`), WithCopySourceIfUnmodifiedSince(modified))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.ETag != `"foo"` {
+		t.Error("wrong etag", req.ETag)
+	}
+	var ossErr *Error
+	_, err = client.Copy("a", "b", WithCopySourceIfMatch(`"bar"`))
+	if !errors.Is(err, ErrPreconditionFailed) || !errors.As(err, &ossErr) || ossErr.Code != "PreconditionFailed" {
+		t.Error("should fail with precondition failed", err)
+	}
+	_, err = client.Copy("a", "b", WithCopySourceIfNoneMatch(`"foo"`))
+	if err != ErrPreconditionFailed {
+		t.Error("should fail with precondition failed", err)
+	}
+}
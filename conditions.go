@@ -0,0 +1,149 @@
+package ossslim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValidateConditions checks extra conditions of PostForm and Form, returns
+// an error describing the first malformed condition, so mistakes are found
+// when the token is generated instead of being rejected by OSS when the file
+// is uploaded. Valid conditions are a Callback, a SuccessAction, an
+// exact-match map like map[string]string{"x-oss-object-acl": "public-read"},
+// or an array like
+// []string{"starts-with", "$key", "user/"}, []string{"eq", "$key", "a.txt"},
+// []interface{}{"content-length-range", 0, 1024} and
+// []interface{}{"in", "$content-type", []string{"image/jpeg", "image/png"}}.
+func ValidateConditions(conditions ...interface{}) error {
+	for _, cond := range conditions {
+		if err := validateCondition(cond); err != nil {
+			return fmt.Errorf("invalid condition %v: %w", cond, err)
+		}
+	}
+	return nil
+}
+
+func validateCondition(cond interface{}) error {
+	switch c := cond.(type) {
+	case Callback:
+		if c.URL == "" {
+			return fmt.Errorf("callback url is empty")
+		}
+		return nil
+	case SuccessAction:
+		if c.Status != 0 && c.Status != 200 && c.Status != 201 && c.Status != 204 {
+			return fmt.Errorf("success action status must be 200, 201 or 204")
+		}
+		return nil
+	case map[string]string:
+		if len(c) == 0 {
+			return fmt.Errorf("empty map")
+		}
+		for key := range c {
+			if key == "" {
+				return fmt.Errorf("empty field name")
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		if len(c) == 0 {
+			return fmt.Errorf("empty map")
+		}
+		for key, value := range c {
+			if key == "" {
+				return fmt.Errorf("empty field name")
+			}
+			if _, ok := value.(string); !ok {
+				return fmt.Errorf("value of %s is not a string", key)
+			}
+		}
+		return nil
+	case []string:
+		items := make([]interface{}, len(c))
+		for i, item := range c {
+			items[i] = item
+		}
+		return validateArrayCondition(items)
+	case []interface{}:
+		return validateArrayCondition(c)
+	}
+	return fmt.Errorf("unsupported type %T", cond)
+}
+
+func validateArrayCondition(items []interface{}) error {
+	if len(items) != 3 {
+		return fmt.Errorf("must have 3 elements")
+	}
+	op, _ := items[0].(string)
+	switch strings.ToLower(op) {
+	case "eq", "starts-with":
+		if err := validateFieldName(items[1]); err != nil {
+			return err
+		}
+		if _, ok := items[2].(string); !ok {
+			return fmt.Errorf("value must be a string")
+		}
+	case "in", "not-in":
+		if err := validateFieldName(items[1]); err != nil {
+			return err
+		}
+		if !isStrings(items[2]) {
+			return fmt.Errorf("value must be a list of strings")
+		}
+	case "content-length-range":
+		min, ok1 := toInt64(items[1])
+		max, ok2 := toInt64(items[2])
+		if !ok1 || !ok2 {
+			return fmt.Errorf("range must be integers")
+		}
+		if min < 0 || min > max {
+			return fmt.Errorf("invalid range %d-%d", min, max)
+		}
+	default:
+		return fmt.Errorf("unknown operator %v", items[0])
+	}
+	return nil
+}
+
+// isStrings reports whether v is a []string or a []interface{} of strings,
+// which is what a list is decoded to from JSON.
+func isStrings(v interface{}) bool {
+	switch v := v.(type) {
+	case []string:
+		return true
+	case []interface{}:
+		for _, item := range v {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func validateFieldName(name interface{}) error {
+	s, ok := name.(string)
+	if !ok || len(s) < 2 || s[0] != '$' {
+		return fmt.Errorf("field name must be a string starting with $")
+	}
+	return nil
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), n == float64(int64(n))
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,135 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newSlowClient returns a client whose server sends some bytes and then
+// blocks until the client disconnects, which closes the returned channel.
+func newSlowClient(t *testing.T) (*Client, chan struct{}) {
+	disconnected := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("a"), 1024))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+			close(disconnected)
+		case <-time.After(5 * time.Second):
+		}
+	})
+	return client, disconnected
+}
+
+func TestDownloadCancel(t *testing.T) {
+	client, _ := newSlowClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := client.DownloadWithContext(ctx, "foo", ioutil.Discard)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal("should be canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatal("download should be stopped promptly", elapsed)
+	}
+}
+
+func TestDownloadAsyncCancel(t *testing.T) {
+	client, disconnected := newSlowClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := client.DownloadAsyncWithContext(ctx, "foo", ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("async download should be stopped promptly")
+	}
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	client, _ := newSlowClient(t)
+	client.DefaultTimeout = 100 * time.Millisecond
+	start := time.Now()
+	_, err := client.Download("foo", ioutil.Discard)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("should time out", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatal("download should be stopped promptly", elapsed)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		if r.Method == "GET" {
+			w.Write([]byte("hello world"))
+		}
+	})
+	var events []string
+	client.OnRequestStart = func(method, url string) {
+		events = append(events, "start "+method+" "+strings.TrimPrefix(url, client.Prefix))
+	}
+	client.OnRequestDone = func(method, url string, status int, bytes int64, duration time.Duration, err error) {
+		events = append(events, fmt.Sprintf("done %s %d %d %v", method, status, bytes, err))
+	}
+	if _, err := client.Upload("foo", strings.NewReader("hello"), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Download("foo", ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	expected := "start PUT /foo,done PUT 200 5 <nil>,start GET /foo,done GET 200 11 <nil>"
+	if strings.Join(events, ",") != expected {
+		t.Error("wrong events", events)
+	}
+}
+
+// TestConcurrentUse should be run with -race.
+func TestConcurrentUse(t *testing.T) {
+	var mu sync.Mutex
+	files := map[string][]byte{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case "PUT":
+			files[r.URL.Path], _ = ioutil.ReadAll(r.Body)
+		case "GET":
+			w.Write(files[r.URL.Path])
+		}
+	})
+	client.MaxBytesPerSecond = 1 << 30
+	client.VerifyCRC64 = true
+	client.CredentialsProvider = func(ctx context.Context) (Credentials, error) {
+		return Credentials{AccessKeyId: "foo", AccessKeySecret: "bar"}, nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("file%d", i)
+			if _, err := client.UploadString(name, name, "text/plain"); err != nil {
+				t.Error(err)
+				return
+			}
+			data, _, err := client.DownloadBytes(name)
+			if err != nil || string(data) != name {
+				t.Error("wrong content", string(data), err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
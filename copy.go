@@ -0,0 +1,211 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// max number of concurrent requests of SetMetaRecursive
+const setMetaConcurrency = 8
+
+type (
+	// UpdateOptions are the new headers and user metadata of a file for
+	// UpdateMeta. Empty fields are removed from the file, except that an
+	// empty ContentType keeps the current content type.
+	UpdateOptions struct {
+		ContentType        string
+		CacheControl       string
+		ContentDisposition string
+		ContentEncoding    string
+		Expires            string
+		Meta               map[string]string
+	}
+
+	copyObjectResult struct {
+		ETag         string
+		LastModified string
+	}
+)
+
+// Copy wraps CopyWithContext using context.Background.
+func (c *Client) Copy(src, dst string, options ...Option) (*Request, error) {
+	return c.CopyWithContext(context.Background(), src, dst, options...)
+}
+
+// CopyWithContext copies remote file src to dst in the same bucket on the
+// server side, no data is transferred through the client. Files larger than
+// 1 GB can not be copied this way. Options like WithCopySourceIfMatch can be
+// used to copy only if the source file is in the expected state.
+func (c *Client) CopyWithContext(ctx context.Context, src, dst string, options ...Option) (*Request, error) {
+	return c.copy(ctx, c.Bucket, src, dst, options)
+}
+
+// CopyFrom wraps CopyFromWithContext using context.Background.
+func (c *Client) CopyFrom(src *Client, srcRemote, dstRemote string, options ...Option) (*Request, error) {
+	return c.CopyFromWithContext(context.Background(), src, srcRemote, dstRemote, options...)
+}
+
+// CopyFromWithContext copies file srcRemote in the bucket of client src to
+// dstRemote in the bucket of c. If both buckets are in the same region, the
+// file is copied on the server side, and the credentials of c must be able
+// to read the source file. Otherwise, the file is downloaded with src and
+// uploaded with c at the same time, with its content type and user metadata.
+func (c *Client) CopyFromWithContext(ctx context.Context, src *Client, srcRemote, dstRemote string, options ...Option) (*Request, error) {
+	srcRegion, dstRegion := src.region(), c.region()
+	if srcRegion == "" || dstRegion == "" || srcRegion == dstRegion {
+		return c.copy(ctx, src.Bucket, srcRemote, dstRemote, options)
+	}
+	info, _, err := src.StatWithContext(ctx, srcRemote)
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := src.DownloadWithContext(ctx, srcRemote, pw)
+		pw.CloseWithError(err)
+	}()
+	defer pr.Close()
+	req := &Request{
+		client:        c,
+		ctx:           ctx,
+		remote:        dstRemote,
+		method:        "PUT",
+		reqBody:       pr,
+		reqBodyLength: info.Size,
+		contentType:   info.ContentType,
+	}
+	return c.upload(req, append([]Option{WithMeta(info.Meta)}, options...))
+}
+
+func (c *Client) copy(ctx context.Context, srcBucket, src, dst string, options []Option) (*Request, error) {
+	var response bytes.Buffer
+	req := &Request{
+		client:   c,
+		ctx:      ctx,
+		remote:   dst,
+		method:   "PUT",
+		respBody: &response,
+	}
+	req.setHeader("x-oss-copy-source", "/"+srcBucket+"/"+url.QueryEscape(strings.TrimPrefix(src, "/")))
+	for _, option := range options {
+		option(req)
+	}
+	if err := req.do(); err != nil {
+		return req, err
+	}
+	if req.NotModified {
+		// OSS responds with 304 if x-oss-copy-source-if-none-match or
+		// x-oss-copy-source-if-modified-since does not hold
+		return req, ErrPreconditionFailed
+	}
+	var result copyObjectResult
+	if err := xml.NewDecoder(&response).Decode(&result); err != nil {
+		return req, err
+	}
+	req.ETag = result.ETag
+	return req, nil
+}
+
+// UpdateMeta wraps UpdateMetaWithContext using context.Background.
+func (c *Client) UpdateMeta(remote string, meta UpdateOptions) error {
+	return c.UpdateMetaWithContext(context.Background(), remote, meta)
+}
+
+// UpdateMetaWithContext replaces headers (like content type and cache
+// control) and user metadata of remote file by copying the file onto itself,
+// the content of the file is not re-uploaded.
+func (c *Client) UpdateMetaWithContext(ctx context.Context, remote string, meta UpdateOptions) error {
+	if meta.ContentType == "" {
+		info, _, err := c.StatWithContext(ctx, remote)
+		if err != nil {
+			return err
+		}
+		meta.ContentType = info.ContentType
+	}
+	_, err := c.CopyWithContext(ctx, remote, remote, meta.option())
+	return err
+}
+
+func (meta UpdateOptions) option() Option {
+	return func(req *Request) {
+		req.setHeader("x-oss-metadata-directive", "REPLACE")
+		req.contentType = meta.ContentType
+		for key, value := range map[string]string{
+			"Cache-Control":       meta.CacheControl,
+			"Content-Disposition": meta.ContentDisposition,
+			"Content-Encoding":    meta.ContentEncoding,
+			"Expires":             meta.Expires,
+		} {
+			if value != "" {
+				req.setHeader(key, value)
+			}
+		}
+		WithMeta(meta.Meta)(req)
+	}
+}
+
+// SetMetaRecursive wraps SetMetaRecursiveWithContext using
+// context.Background.
+func (c *Client) SetMetaRecursive(prefix string, meta UpdateOptions) ([]string, error) {
+	return c.SetMetaRecursiveWithContext(context.Background(), prefix, meta)
+}
+
+// SetMetaRecursiveWithContext is like UpdateMetaWithContext but replaces
+// headers and user metadata of all files in directory prefix and its
+// subdirectories, for example to change cache control of files already
+// uploaded. Files are updated with at most 8 concurrent requests while the
+// directory is being listed. If a file fails to be updated (like files
+// larger than 1 GB), other requests are canceled and the first error is
+// returned. The keys of updated files are returned in alphabetical order.
+func (c *Client) SetMetaRecursiveWithContext(ctx context.Context, prefix string, meta UpdateOptions) (updated []string, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var mu sync.Mutex
+	var once sync.Once
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for i := 0; i < setMetaConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for remote := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				if uerr := c.UpdateMetaWithContext(ctx, remote, meta); uerr != nil {
+					once.Do(func() {
+						err = uerr
+						cancel()
+					})
+					continue
+				}
+				mu.Lock()
+				updated = append(updated, remote)
+				mu.Unlock()
+			}
+		}()
+	}
+	lerr := c.ListPagesWithContext(ctx, prefix, true, func(page ListResult) error {
+		for _, file := range page.Files {
+			select {
+			case jobs <- file.Name:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	close(jobs)
+	wg.Wait()
+	if err == nil {
+		err = lerr
+	}
+	sort.Strings(updated)
+	return
+}
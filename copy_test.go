@@ -0,0 +1,126 @@
+package ossslim
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUpdateMeta(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.Header().Set("Content-Type", "image/png")
+			return
+		}
+		if r.URL.Path != "/a/b c.png" {
+			t.Error("wrong path", r.URL.Path)
+		}
+		if r.Header.Get("x-oss-copy-source") != "/test/a%2Fb+c.png" {
+			t.Error("wrong copy source", r.Header.Get("x-oss-copy-source"))
+		}
+		if r.Header.Get("x-oss-metadata-directive") != "REPLACE" ||
+			r.Header.Get("Content-Type") != "image/png" ||
+			r.Header.Get("Cache-Control") != "max-age=3600" ||
+			r.Header.Get("x-oss-meta-foo") != "bar" {
+			t.Error("wrong headers", r.Header)
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<CopyObjectResult>
+  <ETag>"5B3C1A2E053D763E1B002CC607C5A0FE"</ETag>
+  <LastModified>2012-02-24T02:53:26.000Z</LastModified>
+</CopyObjectResult>`))
+	})
+	err := client.UpdateMeta("/a/b c.png", UpdateOptions{
+		CacheControl: "max-age=3600",
+		Meta:         map[string]string{"foo": "bar"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCopyFrom(t *testing.T) {
+	src := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("x-oss-meta-foo", "bar")
+		if r.Method == "GET" {
+			w.Write([]byte("hello"))
+		}
+	})
+	src.Bucket = "other"
+	var headers []http.Header
+	var bodies []string
+	dst := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		headers = append(headers, r.Header)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("x-oss-copy-source") != "" {
+			w.Write([]byte(`<CopyObjectResult><ETag>"foo"</ETag></CopyObjectResult>`))
+		}
+	})
+	src.Region, dst.Region = "cn-hangzhou", "cn-hangzhou"
+	if _, err := dst.CopyFrom(src, "a.txt", "b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if headers[0].Get("x-oss-copy-source") != "/other/a.txt" || bodies[0] != "" {
+		t.Error("should copy on server side", headers[0])
+	}
+	src.Region = "cn-beijing"
+	req, err := dst.CopyFrom(src, "a.txt", "b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if headers[1].Get("x-oss-copy-source") != "" || bodies[1] != "hello" ||
+		headers[1].Get("Content-Type") != "text/plain" || headers[1].Get("x-oss-meta-foo") != "bar" {
+		t.Error("should download and upload", headers[1], bodies[1])
+	}
+	if req.BytesSent != 5 {
+		t.Error("wrong bytes sent", req.BytesSent)
+	}
+}
+
+func TestSetMetaRecursive(t *testing.T) {
+	var mu sync.Mutex
+	copied := map[string]string{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(listResponse("dir/a.css", "dir/b.js", "dir/sub/c.png")))
+		case "HEAD":
+			w.Header().Set("Content-Type", "text/"+strings.TrimPrefix(r.URL.Path, "/dir/"))
+		case "PUT":
+			if r.URL.Path == "/dir/sub/c.png" && r.Header.Get("Cache-Control") == "no-cache" {
+				w.WriteHeader(400)
+				w.Write([]byte(`<Error><Code>InvalidRequest</Code><Message>invalid</Message></Error>`))
+				return
+			}
+			if r.Header.Get("x-oss-copy-source") != "/test/"+url.PathEscape(strings.TrimPrefix(r.URL.Path, "/")) ||
+				r.Header.Get("x-oss-metadata-directive") != "REPLACE" {
+				t.Error("wrong headers", r.Header)
+			}
+			mu.Lock()
+			copied[r.URL.Path] = r.Header.Get("Content-Type") + " " + r.Header.Get("Cache-Control")
+			mu.Unlock()
+			w.Write([]byte(`<CopyObjectResult><ETag>"foo"</ETag></CopyObjectResult>`))
+		}
+	})
+	updated, err := client.SetMetaRecursive("dir", UpdateOptions{CacheControl: "max-age=31536000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(updated, ",") != "dir/a.css,dir/b.js,dir/sub/c.png" {
+		t.Error("wrong updated files", updated)
+	}
+	if copied["/dir/a.css"] != "text/a.css max-age=31536000" || copied["/dir/sub/c.png"] != "text/sub/c.png max-age=31536000" {
+		t.Error("wrong copied files", copied)
+	}
+	_, err = client.SetMetaRecursive("dir", UpdateOptions{ContentType: "text/plain", CacheControl: "no-cache"})
+	var ossErr *Error
+	if !errors.As(err, &ossErr) || ossErr.Code != "InvalidRequest" {
+		t.Error("wrong error", err)
+	}
+}
@@ -0,0 +1,33 @@
+package ossslim
+
+import (
+	"hash"
+	"hash/crc64"
+	"io"
+	"os"
+)
+
+// CRC64Table is the table of the ECMA polynomial used by OSS in the
+// x-oss-hash-crc64ecma header.
+var CRC64Table = crc64.MakeTable(crc64.ECMA)
+
+// NewCRC64 returns a hash computing the same CRC64 as OSS, which can be
+// compared with CRC64 of FileInfo.
+func NewCRC64() hash.Hash64 {
+	return crc64.New(CRC64Table)
+}
+
+// FileCRC64 returns the CRC64 of local file, the same as the one computed by
+// OSS after the file is uploaded.
+func FileCRC64(localPath string) (uint64, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	sum := NewCRC64()
+	if _, err := io.Copy(sum, file); err != nil {
+		return 0, err
+	}
+	return sum.Sum64(), nil
+}
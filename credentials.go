@@ -0,0 +1,38 @@
+package ossslim
+
+import (
+	"context"
+)
+
+// Credentials are the access key and, for temporary credentials issued by
+// STS, the security token used to sign requests.
+type Credentials struct {
+	AccessKeyId     string
+	AccessKeySecret string
+	SecurityToken   string
+}
+
+// credentials returns the credentials from CredentialsProvider, or the
+// static fields of the client if it is nil.
+func (c *Client) credentials(ctx context.Context) (Credentials, error) {
+	if c.CredentialsProvider != nil {
+		return c.CredentialsProvider(ctx)
+	}
+	return c.staticCredentials(), nil
+}
+
+func (c *Client) staticCredentials() Credentials {
+	return Credentials{
+		AccessKeyId:     c.AccessKeyId,
+		AccessKeySecret: c.AccessKeySecret,
+		SecurityToken:   c.SecurityToken,
+	}
+}
+
+// credentials returns the credentials the request is signed with.
+func (req *Request) credentials() Credentials {
+	if req.creds != nil {
+		return *req.creds
+	}
+	return req.client.staticCredentials()
+}
@@ -0,0 +1,67 @@
+package ossslim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCredentialsProvider(t *testing.T) {
+	var auths, tokens []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		tokens = append(tokens, r.Header.Get("x-oss-security-token")+r.Header.Get("x-amz-security-token"))
+	})
+	client.SecurityToken = "static"
+	if _, _, err := client.Exists("foo"); err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	client.CredentialsProvider = func(ctx context.Context) (Credentials, error) {
+		n++
+		if n == 3 {
+			return Credentials{}, errors.New("expired")
+		}
+		return Credentials{
+			AccessKeyId:     "STS.key" + string(rune('0'+n)),
+			AccessKeySecret: "secret",
+			SecurityToken:   "token" + string(rune('0'+n)),
+		}, nil
+	}
+	if _, _, err := client.Exists("foo"); err != nil {
+		t.Fatal(err)
+	}
+	client.Flavor = FlavorS3
+	if _, _, err := client.Exists("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.Exists("foo"); err == nil || err.Error() != "expired" {
+		t.Error("error of provider should be returned", err)
+	}
+	if len(auths) != 3 || !strings.HasPrefix(auths[0], "OSS foo:") ||
+		!strings.HasPrefix(auths[1], "OSS STS.key1:") || !strings.HasPrefix(auths[2], "AWS STS.key2:") {
+		t.Error("wrong authorization", auths)
+	}
+	if strings.Join(tokens, ",") != "static,token1,token2" {
+		t.Error("wrong security tokens", tokens)
+	}
+}
+
+func TestPostFormSecurityToken(t *testing.T) {
+	client := &Client{AccessKeyId: "foo", AccessKeySecret: "bar", SecurityToken: "token", Bucket: "test"}
+	fields := client.PostForm("a.txt", 0, 0)
+	if fields["x-oss-security-token"] != "token" || fields["OSSAccessKeyId"] != "foo" {
+		t.Error("wrong fields", fields)
+	}
+	client.CredentialsProvider = func(ctx context.Context) (Credentials, error) {
+		return Credentials{}, errors.New("expired")
+	}
+	if fields := client.PostForm("a.txt", 0, 0); fields != nil {
+		t.Error("fields should be nil", fields)
+	}
+	if _, err := client.Form("a.txt", 0, 0); err == nil {
+		t.Error("error of provider should be returned")
+	}
+}
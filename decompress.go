@@ -0,0 +1,35 @@
+package ossslim
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// gzipReader decompresses gzip data read from r. The gzip header is read on
+// first Read, so an empty body is read as empty instead of an error.
+type gzipReader struct {
+	r  io.Reader
+	gz *gzip.Reader
+}
+
+// WithDecompress makes a download request decompress the file if it is
+// stored with Content-Encoding: gzip, so the original bytes are written to
+// the response body. Without it, the bytes are written as stored. CRC64 is
+// not verified (see VerifyCRC64) for requests with this option.
+func WithDecompress() Option {
+	return func(req *Request) {
+		req.decompress = true
+		req.setHeader("Accept-Encoding", "gzip")
+	}
+}
+
+func (r *gzipReader) Read(p []byte) (int, error) {
+	if r.gz == nil {
+		gz, err := gzip.NewReader(r.r)
+		if err != nil {
+			return 0, err
+		}
+		r.gz = gz
+	}
+	return r.gz.Read(p)
+}
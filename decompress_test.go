@@ -0,0 +1,86 @@
+package ossslim
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestDecompress(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte("hello world"))
+	gz.Close()
+	content := gzipped.Bytes()
+	var encodings []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(content)
+	})
+	client.VerifyCRC64 = true
+	var buf bytes.Buffer
+	if _, err := client.Download("foo", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), gzipped.Bytes()) {
+		t.Error("file should not be decompressed")
+	}
+	buf.Reset()
+	if _, err := client.Download("foo", &buf, WithDecompress()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello world" {
+		t.Error("file should be decompressed", buf.String())
+	}
+	content = nil
+	buf.Reset()
+	if _, err := client.Download("foo", &buf, WithDecompress()); err != nil || buf.Len() != 0 {
+		t.Error("empty file should be empty", buf.Len(), err)
+	}
+	if len(encodings) != 3 || encodings[0] != "identity" || encodings[1] != "gzip" {
+		t.Error("wrong Accept-Encoding", encodings)
+	}
+}
+
+// TestDecompressPreviousDefault checks that WithDecompress downloads the same
+// bytes as a plain http.Client, which decompressed gzip files transparently
+// and was what Download returned before Accept-Encoding: identity was sent.
+func TestDecompressPreviousDefault(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte("hello world"))
+	gz.Close()
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(gzipped.Bytes())
+	})
+	resp, err := http.Get(client.Prefix + "/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(previous) != "hello world" {
+		t.Fatal("http.Client should decompress the file", string(previous))
+	}
+	var buf bytes.Buffer
+	if _, err := client.Download("foo", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(buf.Bytes(), previous) {
+		t.Error("file should be downloaded as stored by default")
+	}
+	buf.Reset()
+	if _, err := client.Download("foo", &buf, WithDecompress()); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), previous) {
+		t.Error("WithDecompress should download the previous default", buf.String())
+	}
+}
@@ -0,0 +1,146 @@
+package ossslim
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// maxDeleteKeys is the maximum number of keys in a single delete request.
+const maxDeleteKeys = 1000
+
+// DeleteRecursive wraps DeleteRecursiveWithContext using context.Background.
+func (c *Client) DeleteRecursive(prefix string) (DeleteResult, error) {
+	return c.DeleteRecursiveWithContext(context.Background(), prefix)
+}
+
+// DeleteRecursiveWithContext deletes all files in directory prefix and its
+// subdirectories, returns the keys of deleted files and the keys that failed
+// to be deleted with their errors. An empty prefix deletes all files in the
+// bucket. Each page of at most 1000 listed keys is deleted before the next
+// page is listed. If a page fails to be deleted, its keys are added to Errors
+// of the result and the remaining pages are still deleted. The returned
+// error is the first error encountered.
+func (c *Client) DeleteRecursiveWithContext(ctx context.Context, prefix string) (result DeleteResult, err error) {
+	lerr := c.ListPagesWithContext(ctx, prefix, true, func(page ListResult) error {
+		keys := make([]string, 0, len(page.Files))
+		for _, file := range page.Files {
+			keys = append(keys, file.Name)
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		res, derr := c.DeleteVerboseWithContext(ctx, keys...)
+		if derr != nil {
+			if err == nil {
+				err = derr
+			}
+			result.Errors = append(result.Errors, deleteErrors(keys, derr)...)
+			// don't send more requests once context is done
+			return ctx.Err()
+		}
+		result.Deleted = append(result.Deleted, res.Deleted...)
+		result.Errors = append(result.Errors, res.Errors...)
+		return nil
+	})
+	if err == nil {
+		err = lerr
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return
+}
+
+// deleteErrors returns a DeleteError with err for each key.
+func deleteErrors(keys []string, err error) (errs []DeleteError) {
+	var ossErr *Error
+	code := ""
+	if errors.As(err, &ossErr) {
+		code = ossErr.Code
+	}
+	for _, key := range keys {
+		errs = append(errs, DeleteError{
+			Key:     key,
+			Code:    code,
+			Message: err.Error(),
+		})
+	}
+	return
+}
+
+// delete deletes remotes in batches of maxDeleteKeys keys using at most
+// DeleteConcurrency workers, returns the combined result and the first
+// error. Once a batch fails, batches in flight are completed but no more
+// batches are sent, keys of failed and unsent batches are added to Errors of
+// the result.
+func (c *Client) delete(ctx context.Context, remotes []string, quiet bool) (result DeleteResult, err error) {
+	if len(remotes) <= maxDeleteKeys {
+		return c.deleteBatch(ctx, remotes, quiet)
+	}
+	var batches [][]string
+	for i := 0; i < len(remotes); i += maxDeleteKeys {
+		end := i + maxDeleteKeys
+		if end > len(remotes) {
+			end = len(remotes)
+		}
+		batches = append(batches, remotes[i:end])
+	}
+	workers := c.DeleteConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]DeleteResult, len(batches))
+	sent := make([]bool, len(batches))
+	failed := make(chan struct{})
+	var once sync.Once
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				select {
+				case <-failed:
+					// don't send more batches after a batch fails
+					continue
+				default:
+				}
+				sent[i] = true
+				res, derr := c.deleteBatch(ctx, batches[i], quiet)
+				if derr != nil {
+					once.Do(func() {
+						err = derr
+						close(failed)
+					})
+					res = DeleteResult{Errors: deleteErrors(batches[i], derr)}
+				}
+				results[i] = res
+			}
+		}()
+	}
+loop:
+	for i := range batches {
+		select {
+		case jobs <- i:
+		case <-failed:
+			break loop
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err == nil {
+		err = ctx.Err()
+	}
+	for i, res := range results {
+		if !sent[i] {
+			res.Errors = deleteErrors(batches[i], err)
+		}
+		result.Deleted = append(result.Deleted, res.Deleted...)
+		result.Errors = append(result.Errors, res.Errors...)
+	}
+	return
+}
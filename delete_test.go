@@ -0,0 +1,215 @@
+package ossslim
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func listResponse(keys ...string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult><IsTruncated>false</IsTruncated>`)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "<Contents><Key>%s</Key></Contents>", key)
+	}
+	b.WriteString("</ListBucketResult>")
+	return b.String()
+}
+
+func TestDeleteRecursive(t *testing.T) {
+	keys := []string{"dir/"}
+	for i := 0; i < 1001; i++ {
+		keys = append(keys, fmt.Sprintf("dir/%04d", i))
+	}
+	batches := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			if prefix := r.URL.Query().Get("prefix"); prefix != "dir/" {
+				t.Error("wrong prefix", prefix)
+			}
+			// pages of 1000 keys like OSS
+			if r.URL.Query().Get("marker") == "" {
+				page := strings.Replace(listResponse(keys[:1000]...), "<IsTruncated>false</IsTruncated>",
+					"<IsTruncated>true</IsTruncated><NextMarker>"+keys[999]+"</NextMarker>", 1)
+				w.Write([]byte(page))
+			} else {
+				w.Write([]byte(listResponse(keys[1000:]...)))
+			}
+			return
+		}
+		batches++
+		body, _ := ioutil.ReadAll(r.Body)
+		if batches == 1 {
+			w.WriteHeader(403)
+			w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>denied</Message></Error>`))
+			return
+		}
+		if !bytes.Contains(body, []byte("<Key>dir/0999</Key>")) {
+			t.Error("wrong body", string(body))
+		}
+		w.Write([]byte(`<DeleteResult><Deleted><Key>dir/0999</Key></Deleted>` +
+			`<Error><Key>dir/1000</Key><Code>AccessDenied</Code><Message>denied</Message></Error></DeleteResult>`))
+	})
+	result, err := client.DeleteRecursive("dir")
+	var ossErr *Error
+	if !errors.As(err, &ossErr) || ossErr.Code != "AccessDenied" {
+		t.Error("wrong error", err)
+	}
+	if batches != 2 {
+		t.Error("wrong number of batches", batches)
+	}
+	if len(result.Deleted) != 1 || result.Deleted[0] != "dir/0999" {
+		t.Error("wrong deleted", result.Deleted)
+	}
+	if len(result.Errors) != 1001 || result.Errors[0].Key != "dir/" || result.Errors[0].Code != "AccessDenied" ||
+		result.Errors[1000].Key != "dir/1000" {
+		t.Error("wrong errors", len(result.Errors))
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write([]byte(listResponse("dir/a")))
+			return
+		}
+		t.Error("unexpected request", r.Method, r.URL)
+	})
+	var logs bytes.Buffer
+	client.DryRun = true
+	client.Logger = log.New(&logs, "", 0)
+	if _, err := client.Upload("foo", strings.NewReader("bar"), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Delete("foo"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.DeleteRecursive("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Deleted) != 1 || result.Deleted[0] != "dir/a" {
+		t.Error("wrong deleted", result.Deleted)
+	}
+	expected := "dry run: upload " + client.Prefix + "/foo\n" +
+		"dry run: delete " + client.Prefix + "/foo\n" +
+		"dry run: delete " + client.Prefix + "/dir/a\n"
+	if logs.String() != expected {
+		t.Errorf("wrong logs %q", logs.String())
+	}
+}
+
+func TestDeleteBatches(t *testing.T) {
+	var mu sync.Mutex
+	var batches []int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		keys := regexp.MustCompile(`<Key>(.*?)</Key>`).FindAllSubmatch(body, -1)
+		mu.Lock()
+		batches = append(batches, len(keys))
+		mu.Unlock()
+		var b strings.Builder
+		b.WriteString("<DeleteResult>")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "<Deleted><Key>%s</Key></Deleted>", key[1])
+		}
+		b.WriteString("</DeleteResult>")
+		w.Write([]byte(b.String()))
+	})
+	client.DeleteConcurrency = 2
+	var keys []string
+	for i := 0; i < 2500; i++ {
+		keys = append(keys, fmt.Sprintf("%04d", i))
+	}
+	result, err := client.DeleteVerbose(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Ints(batches)
+	if fmt.Sprint(batches) != "[500 1000 1000]" {
+		t.Error("wrong batches", batches)
+	}
+	if len(result.Deleted) != 2500 || result.Deleted[0] != "0000" || result.Deleted[2499] != "2499" {
+		t.Error("wrong deleted", len(result.Deleted))
+	}
+	if err := client.Delete(keys...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteBatchesError(t *testing.T) {
+	var requests int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(403)
+		w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>denied</Message></Error>`))
+	})
+	keys := make([]string, 5000)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+	}
+	err := client.Delete(keys...)
+	var ossErr *Error
+	if !errors.As(err, &ossErr) || ossErr.Code != "AccessDenied" {
+		t.Error("wrong error", err)
+	}
+	if requests != 1 {
+		t.Error("should stop after first failed batch", requests)
+	}
+}
+
+func TestDeleteBatchesInFlight(t *testing.T) {
+	var mu sync.Mutex
+	var deleted []string
+	failed := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		keys := regexp.MustCompile(`<Key>(.*?)</Key>`).FindAllSubmatch(body, -1)
+		if string(keys[0][1]) == "0000" {
+			w.WriteHeader(403)
+			w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>denied</Message></Error>`))
+			close(failed)
+			return
+		}
+		// complete after the first batch fails
+		<-failed
+		var b strings.Builder
+		b.WriteString("<DeleteResult>")
+		mu.Lock()
+		for _, key := range keys {
+			deleted = append(deleted, string(key[1]))
+			fmt.Fprintf(&b, "<Deleted><Key>%s</Key></Deleted>", key[1])
+		}
+		mu.Unlock()
+		b.WriteString("</DeleteResult>")
+		w.Write([]byte(b.String()))
+	})
+	client.DeleteConcurrency = 2
+	keys := make([]string, 5000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%04d", i)
+	}
+	result, err := client.DeleteVerbose(keys...)
+	var ossErr *Error
+	if !errors.As(err, &ossErr) || ossErr.Code != "AccessDenied" {
+		t.Error("wrong error", err)
+	}
+	// batches in flight are completed, other keys are in errors
+	sort.Strings(deleted)
+	if len(deleted) == 0 || len(deleted) > 2000 || strings.Join(result.Deleted, ",") != strings.Join(deleted, ",") {
+		t.Error("wrong deleted", len(result.Deleted), len(deleted))
+	}
+	if len(result.Deleted)+len(result.Errors) != len(keys) || result.Errors[0].Key != "0000" ||
+		result.Errors[0].Code != "AccessDenied" || result.Errors[len(result.Errors)-1].Key != "4999" {
+		t.Error("wrong errors", len(result.Errors))
+	}
+}
@@ -0,0 +1,52 @@
+package ossslim
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// WithDetectContentType makes an upload request without content type detect
+// it from the first 512 bytes of the body using http.DetectContentType,
+// instead of using "application/octet-stream". A seekable body (like
+// *os.File) is seeked back after the bytes are read, other bodies have the
+// bytes buffered. For UploadFile, it applies if the content type can not be
+// determined by the file extension.
+func WithDetectContentType() Option {
+	return func(req *Request) {
+		req.detectContentType = true
+	}
+}
+
+// detectBodyContentType sets content type of the request from the first 512
+// bytes of the body.
+func (req *Request) detectBodyContentType() error {
+	if req.reqBody == nil {
+		return nil
+	}
+	head := make([]byte, 512)
+	seeker, seekable := req.reqBody.(io.ReadSeeker)
+	var start int64
+	if seekable {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			return err
+		}
+	}
+	n, err := io.ReadFull(req.reqBody, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	head = head[:n]
+	if seekable {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+	} else {
+		req.reqBody = io.MultiReader(bytes.NewReader(head), req.reqBody)
+	}
+	if n > 0 {
+		req.contentType = http.DetectContentType(head)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package ossslim
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectContentType(t *testing.T) {
+	var contentType, body string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+	})
+	html := "<!DOCTYPE html><html><body>" + strings.Repeat("a", 1000) + "</body></html>"
+	// not seekable
+	reader := ioutil.NopCloser(strings.NewReader(html))
+	if _, err := client.Upload("foo", reader, nil, "", WithDetectContentType()); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "text/html; charset=utf-8" || body != html {
+		t.Error("wrong upload", contentType, len(body))
+	}
+	if _, err := client.Upload("foo", strings.NewReader(html), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "application/octet-stream" {
+		t.Error("content type should not be detected without option", contentType)
+	}
+	if _, err := client.UploadBytes("foo", pngData, "", WithDetectContentType()); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "image/png" || body != string(pngData) {
+		t.Error("wrong upload", contentType, len(body))
+	}
+	if _, err := client.UploadString("foo", html, "text/plain", WithDetectContentType()); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "text/plain" {
+		t.Error("given content type should be used", contentType)
+	}
+
+	local := filepath.Join(t.TempDir(), "image")
+	if err := ioutil.WriteFile(local, pngData, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UploadFile("foo", local, "", WithDetectContentType()); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "image/png" || body != string(pngData) {
+		t.Error("wrong upload", contentType, len(body))
+	}
+	if _, err := client.UploadFile("foo", local, ""); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "application/octet-stream" {
+		t.Error("content type should not be detected without option", contentType)
+	}
+	os.Rename(local, local+".txt")
+	if _, err := client.UploadFile("foo", local+".txt", "", WithDetectContentType()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(contentType, "text/plain") {
+		t.Error("extension should be used", contentType)
+	}
+}
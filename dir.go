@@ -0,0 +1,25 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+)
+
+// CreateDir wraps CreateDirWithContext using context.Background.
+func (c *Client) CreateDir(remote string) (*Request, error) {
+	return c.CreateDirWithContext(context.Background(), remote)
+}
+
+// CreateDirWithContext creates an empty file whose key is remote with a
+// trailing slash (like "a/b/"), which is shown as an empty directory in the
+// OSS console and other tools. It is not required to upload files into a
+// directory. Files in the directory are not affected if it already exists.
+func (c *Client) CreateDirWithContext(ctx context.Context, remote string) (*Request, error) {
+	remote = strings.Trim(remote, "/")
+	if remote == "" {
+		return nil, errors.New("directory name is empty")
+	}
+	return c.UploadWithContext(ctx, remote+"/", bytes.NewReader(nil), nil, "")
+}
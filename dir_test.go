@@ -0,0 +1,31 @@
+package ossslim
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCreateDir(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.ContentLength != 0 {
+			t.Error("directory should be empty")
+		}
+	})
+	for _, dir := range []string{"a/b", "/a/b/"} {
+		req, err := client.CreateDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.URL() != client.Prefix+"/a/b/" {
+			t.Error("wrong url", req.URL())
+		}
+	}
+	if _, err := client.CreateDir("/"); err == nil {
+		t.Error("empty directory name should fail")
+	}
+	if len(requests) != 2 || requests[0] != "PUT /a/b/" || requests[1] != "PUT /a/b/" {
+		t.Error("wrong requests", requests)
+	}
+}
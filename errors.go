@@ -0,0 +1,130 @@
+package ossslim
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	// Error is returned when OSS responds with an error status code. Code
+	// is the OSS error code like "NoSuchKey" or "AccessDenied", it is empty
+	// if the response body is not an OSS error (for example a HEAD request).
+	// ServerTime is only available in RequestTimeTooSkewed error. Body is
+	// the raw response body, which helps to diagnose unexpected responses
+	// like HTML error pages of a misconfigured proxy or CNAME.
+	Error struct {
+		StatusCode int
+		Code       string
+		Message    string
+		RequestId  string
+		HostId     string
+		ServerTime time.Time
+		Body       []byte
+	}
+
+	// WrongRegionError is returned when the bucket must be accessed using
+	// another endpoint, usually because the bucket is in a different region
+	// than the one in Prefix. Endpoint is the endpoint suggested by OSS, for
+	// example oss-cn-beijing.aliyuncs.com, in the error response or in the
+	// Location header of a redirect, which is not followed (see HTTPClient).
+	WrongRegionError struct {
+		Endpoint string
+		Err      *Error
+	}
+
+	// SignatureError is returned when OSS rejects the signature of a request
+	// with SignatureDoesNotMatch. ServerStringToSign is the string OSS
+	// signed, ClientStringToSign is the string the client signed, the
+	// signature is rejected because they are different.
+	SignatureError struct {
+		ServerStringToSign string
+		ClientStringToSign string
+		SignatureProvided  string
+		Err                *Error
+	}
+
+	// AuthError is returned by Ping if OSS rejects the credentials, for
+	// example wrong access key ID or secret or no permission.
+	AuthError struct {
+		Err *Error
+	}
+
+	// NetworkError is returned by Ping if OSS can not be reached.
+	NetworkError struct {
+		Err error
+	}
+)
+
+var (
+	// ErrTooLarge is returned if the file to download is larger than the
+	// limit set by WithMaxSize.
+	ErrTooLarge = errors.New("file is too large")
+
+	// ErrPreconditionFailed is returned by Copy if a condition like
+	// WithCopySourceIfMatch does not hold. An *Error of status code 412 also
+	// matches it with errors.Is.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return e.Message
+}
+
+// Is returns true if target is ErrPreconditionFailed and the status code is
+// 412.
+func (e *Error) Is(target error) bool {
+	return target == ErrPreconditionFailed && e.StatusCode == 412
+}
+
+func (e *WrongRegionError) Error() string {
+	return fmt.Sprintf("wrong region, bucket must be accessed using endpoint %s: %s", e.Endpoint, e.Err)
+}
+
+func (e *WrongRegionError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SignatureError) Error() string {
+	server := strings.Split(e.ServerStringToSign, "\n")
+	client := strings.Split(e.ClientStringToSign, "\n")
+	for i := 0; i < len(server) || i < len(client); i++ {
+		var s, c string
+		if i < len(server) {
+			s = server[i]
+		}
+		if i < len(client) {
+			c = client[i]
+		}
+		if s != c {
+			return fmt.Sprintf("%s: line %d of string to sign differs, server: %q, client: %q",
+				e.Err, i+1, s, c)
+		}
+	}
+	return e.Err.Error()
+}
+
+func (e *SignatureError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AuthError) Error() string {
+	return "authentication failed: " + e.Err.Error()
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+func (e *NetworkError) Error() string {
+	return "network error: " + e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,70 @@
+package ossslim
+
+import (
+	"context"
+	"sync"
+)
+
+// max number of concurrent HEAD requests of ExistsMany
+const existsConcurrency = 16
+
+// ExistsMany wraps ExistsManyWithContext using context.Background.
+func (c *Client) ExistsMany(remotes []string) (map[string]bool, error) {
+	return c.ExistsManyWithContext(context.Background(), remotes)
+}
+
+// ExistsManyWithContext checks whether each of remotes exists using at most
+// 16 concurrent HEAD requests, returns a map from each remote to whether it
+// exists. If any request fails, other requests are canceled and the first
+// error is returned. To check many files in the same directory, listing the
+// directory with List may need fewer requests.
+func (c *Client) ExistsManyWithContext(ctx context.Context, remotes []string) (map[string]bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	result := make(map[string]bool, len(remotes))
+	var mu sync.Mutex
+	var once sync.Once
+	var firstErr error
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	workers := existsConcurrency
+	if len(remotes) < workers {
+		workers = len(remotes)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for remote := range jobs {
+				exists, _, err := c.ExistsWithContext(ctx, remote)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				mu.Lock()
+				result[remote] = exists
+				mu.Unlock()
+			}
+		}()
+	}
+loop:
+	for _, remote := range remotes {
+		select {
+		case jobs <- remote:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package ossslim
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExistsMany(t *testing.T) {
+	var mu sync.Mutex
+	var active, maxActive int
+	release := make(chan struct{})
+	var once sync.Once
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		if active == existsConcurrency {
+			once.Do(func() { close(release) })
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		active--
+		mu.Unlock()
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/yes"):
+		case r.URL.Path == "/denied":
+			w.WriteHeader(403)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	var remotes []string
+	for i := 0; i < 50; i++ {
+		if i%2 == 0 {
+			remotes = append(remotes, fmt.Sprintf("yes%d", i))
+		} else {
+			remotes = append(remotes, fmt.Sprintf("no%d", i))
+		}
+	}
+	result, err := client.ExistsMany(remotes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != len(remotes) {
+		t.Error("wrong number of results", len(result))
+	}
+	for _, remote := range remotes {
+		if result[remote] != strings.HasPrefix(remote, "yes") {
+			t.Error("wrong result for", remote)
+		}
+	}
+	if maxActive != existsConcurrency {
+		t.Error("wrong concurrency", maxActive)
+	}
+	if _, err := client.ExistsMany(append(remotes, "denied")); err == nil {
+		t.Error("error should be returned")
+	}
+	if result, err := client.ExistsMany(nil); err != nil || len(result) != 0 {
+		t.Error("wrong result", result, err)
+	}
+}
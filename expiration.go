@@ -0,0 +1,44 @@
+package ossslim
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WithExpires sets the Expires header of the uploaded file, which is
+// returned in downloads to tell browsers and CDNs when to stop caching the
+// file. OSS does not delete the file when it expires, use lifecycle rules of
+// the bucket to delete files automatically.
+func WithExpires(t time.Time) Option {
+	return func(req *Request) {
+		req.setHeader("Expires", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// parseExpiration parses x-oss-expiration header like
+// expiry-date="Fri, 23 Dec 2012 00:00:00 GMT", rule-id="Rule1".
+func parseExpiration(header string) (expiry time.Time, ruleId string) {
+	for header != "" {
+		var pair string
+		// dates contain commas, so split at commas after closing quotes
+		if i := strings.Index(header, `",`); i >= 0 {
+			pair, header = header[:i+1], header[i+2:]
+		} else {
+			pair, header = header, ""
+		}
+		i := strings.IndexByte(pair, '=')
+		if i < 0 {
+			continue
+		}
+		key := strings.TrimSpace(pair[:i])
+		value := strings.Trim(strings.TrimSpace(pair[i+1:]), `"`)
+		switch key {
+		case "expiry-date":
+			expiry, _ = http.ParseTime(value)
+		case "rule-id":
+			ruleId = value
+		}
+	}
+	return
+}
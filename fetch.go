@@ -0,0 +1,103 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"net/url"
+	"strings"
+)
+
+type (
+	// FetchTask is the state of a task created by FetchObject. State is
+	// "Running", "Success" or "Failed", ErrorMsg is the reason of a failed
+	// task.
+	FetchTask struct {
+		TaskId   string
+		State    string
+		ErrorMsg string
+		Url      string `xml:"TaskInfo>Url"`
+		Object   string `xml:"TaskInfo>Object"`
+	}
+
+	asyncFetchTaskConfiguration struct {
+		XMLName xml.Name `xml:"AsyncFetchTaskConfiguration"`
+		Url     string
+		Object  string
+	}
+
+	asyncFetchTaskResult struct {
+		TaskId string
+	}
+)
+
+// FetchObject wraps FetchObjectWithContext using context.Background.
+func (c *Client) FetchObject(remote, sourceURL string) (string, error) {
+	return c.FetchObjectWithContext(context.Background(), remote, sourceURL)
+}
+
+// FetchObjectWithContext makes OSS download sourceURL and save it as remote
+// file, the data is not transferred through the client. The file is fetched
+// asynchronously, use GetFetchTask with the returned task ID to get its
+// state. The fetch feature (asyncFetch) must be enabled for the bucket,
+// otherwise an *Error is returned.
+func (c *Client) FetchObjectWithContext(ctx context.Context, remote, sourceURL string) (string, error) {
+	var reqBody bytes.Buffer
+	reqBody.WriteString(xml.Header)
+	if err := xml.NewEncoder(&reqBody).Encode(asyncFetchTaskConfiguration{
+		Url:    sourceURL,
+		Object: strings.TrimPrefix(remote, "/"),
+	}); err != nil {
+		return "", err
+	}
+	md5sum := md5.Sum(reqBody.Bytes())
+	var response bytes.Buffer
+	req := &Request{
+		client:     c,
+		ctx:        ctx,
+		remote:     "/",
+		method:     "POST",
+		reqBody:    bytes.NewReader(reqBody.Bytes()),
+		contentMd5: base64.StdEncoding.EncodeToString(md5sum[:]),
+		respBody:   &response,
+		queries:    url.Values{"asyncFetch": []string{""}},
+	}
+	if err := req.do(); err != nil {
+		return "", err
+	}
+	var result asyncFetchTaskResult
+	if err := xml.NewDecoder(&response).Decode(&result); err != nil {
+		return "", err
+	}
+	return result.TaskId, nil
+}
+
+// GetFetchTask wraps GetFetchTaskWithContext using context.Background.
+func (c *Client) GetFetchTask(taskId string) (*FetchTask, error) {
+	return c.GetFetchTaskWithContext(context.Background(), taskId)
+}
+
+// GetFetchTaskWithContext returns the state of the task created by
+// FetchObject.
+func (c *Client) GetFetchTaskWithContext(ctx context.Context, taskId string) (*FetchTask, error) {
+	var response bytes.Buffer
+	req := &Request{
+		client:   c,
+		ctx:      ctx,
+		remote:   "/",
+		method:   "GET",
+		respBody: &response,
+		queries:  url.Values{"asyncFetch": []string{""}},
+	}
+	req.setHeader("x-oss-task-id", taskId)
+	if err := req.do(); err != nil {
+		return nil, err
+	}
+	var task FetchTask
+	if err := xml.NewDecoder(&response).Decode(&task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
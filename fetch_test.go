@@ -0,0 +1,53 @@
+package ossslim
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFetchObject(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.RawQuery+" "+r.Header.Get("x-oss-task-id")+
+			strings.TrimPrefix(string(body), `<?xml version="1.0" encoding="UTF-8"?>`+"\n"))
+		if r.Method == "POST" {
+			w.Write([]byte(`<AsyncFetchTaskResult><TaskId>foo</TaskId></AsyncFetchTaskResult>`))
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<AsyncFetchTaskInfo>
+  <TaskId>foo</TaskId>
+  <State>Failed</State>
+  <ErrorMsg>FetchServerError</ErrorMsg>
+  <TaskInfo>
+    <Url>https://example.com/a.png</Url>
+    <Object>images/a.png</Object>
+  </TaskInfo>
+</AsyncFetchTaskInfo>`))
+	})
+	taskId, err := client.FetchObject("/images/a.png", "https://example.com/a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if taskId != "foo" {
+		t.Error("wrong task id", taskId)
+	}
+	task, err := client.GetFetchTask(taskId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.TaskId != "foo" || task.State != "Failed" || task.ErrorMsg != "FetchServerError" ||
+		task.Url != "https://example.com/a.png" || task.Object != "images/a.png" {
+		t.Error("wrong task", task)
+	}
+	expected := []string{
+		"POST asyncFetch <AsyncFetchTaskConfiguration><Url>https://example.com/a.png</Url><Object>images/a.png</Object></AsyncFetchTaskConfiguration>",
+		"GET asyncFetch foo",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Error("wrong requests", requests)
+	}
+}
@@ -0,0 +1,78 @@
+package ossslim
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Form contains everything a frontend needs to upload a file to OSS in a
+// multipart form POST request: the URL to post to, name of the file field,
+// and other fields generated by PostForm. The file field must be the last
+// field in the form. Form can be encoded to JSON and sent to the frontend.
+type Form struct {
+	Action    string            `json:"action"`
+	FileField string            `json:"fileField"`
+	Fields    map[string]string `json:"fields"`
+}
+
+// SuccessAction can be provided in extraConditions of PostForm and Form to
+// control the response of a successful upload. If Redirect is not empty, the
+// browser is redirected to it with bucket, key, etag (and other) query
+// parameters. Otherwise, the response has status code Status, which can be
+// 200, 201 (with an XML body) or 204 (default).
+type SuccessAction struct {
+	Redirect string
+	Status   int
+}
+
+func (action SuccessAction) fields() map[string]string {
+	fields := map[string]string{}
+	if action.Redirect != "" {
+		fields["success_action_redirect"] = action.Redirect
+	}
+	if action.Status != 0 {
+		fields["success_action_status"] = strconv.Itoa(action.Status)
+	}
+	return fields
+}
+
+// Form is like PostForm but returns a Form including the URL of the bucket.
+// An error is returned if extraConditions are invalid, see
+// ValidateConditions.
+func (c *Client) Form(key string, maxSize int64, duration time.Duration, extraConditions ...interface{}) (Form, error) {
+	if err := ValidateConditions(extraConditions...); err != nil {
+		return Form{}, err
+	}
+	creds, err := c.credentials(context.Background())
+	if err != nil {
+		return Form{}, err
+	}
+	return Form{
+		Action:    strings.TrimSuffix(c.Prefix, "/") + "/",
+		FileField: "file",
+		Fields:    c.postForm(creds, key, maxSize, duration, extraConditions...),
+	}, nil
+}
+
+// HTML returns a minimal HTML form with hidden fields, a file input and a
+// submit button, which can be used to test uploads in a browser.
+func (f Form) HTML() string {
+	keys := make([]string, 0, len(f.Fields))
+	for key := range f.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(`<form action="` + html.EscapeString(f.Action) + `" method="post" enctype="multipart/form-data">` + "\n")
+	for _, key := range keys {
+		b.WriteString(`  <input type="hidden" name="` + html.EscapeString(key) + `" value="` + html.EscapeString(f.Fields[key]) + `">` + "\n")
+	}
+	b.WriteString(`  <input type="file" name="` + html.EscapeString(f.FileField) + `">` + "\n")
+	b.WriteString(`  <input type="submit" value="Upload">` + "\n")
+	b.WriteString("</form>\n")
+	return b.String()
+}
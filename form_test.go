@@ -0,0 +1,99 @@
+package ossslim
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestForm(t *testing.T) {
+	client := &Client{
+		AccessKeyId:     "foo",
+		AccessKeySecret: "bar",
+		Prefix:          "https://test.oss-cn-hangzhou.aliyuncs.com",
+		Bucket:          "test",
+	}
+	form, err := client.Form("/a&b.txt", 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Action != "https://test.oss-cn-hangzhou.aliyuncs.com/" || form.FileField != "file" {
+		t.Error("wrong form", form.Action, form.FileField)
+	}
+	if form.Fields["key"] != "a&b.txt" || form.Fields["OSSAccessKeyId"] != "foo" {
+		t.Error("wrong fields", form.Fields)
+	}
+	if _, err := client.Form("a.txt", 0, 0, []string{"starts-with", "key", "a"}); err == nil {
+		t.Error("invalid condition should return error")
+	}
+	j, err := json.Marshal(form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(j), `{"action":"https://test.oss-cn-hangzhou.aliyuncs.com/","fileField":"file","fields":{`) {
+		t.Error("wrong json", string(j))
+	}
+	h := form.HTML()
+	if !strings.Contains(h, `<input type="hidden" name="key" value="a&amp;b.txt">`) {
+		t.Error("wrong html", h)
+	}
+	if strings.Index(h, `type="file"`) < strings.Index(h, `name="signature"`) {
+		t.Error("file field must be the last field", h)
+	}
+}
+
+func TestValidateConditions(t *testing.T) {
+	valid := []interface{}{
+		Callback{URL: "https://example.com/callback"},
+		SuccessAction{Status: 201},
+		map[string]string{"x-oss-object-acl": "public-read"},
+		map[string]interface{}{"success_action_status": "201"},
+		[]string{"starts-with", "$content-type", "image/"},
+		[]string{"eq", "$key", "a.txt"},
+		[]interface{}{"content-length-range", 0, int64(1024)},
+		[]interface{}{"in", "$content-type", []string{"image/jpeg", "image/png"}},
+		[]interface{}{"not-in", "$content-type", []interface{}{"image/gif", "image/bmp"}},
+	}
+	if err := ValidateConditions(valid...); err != nil {
+		t.Error(err)
+	}
+	for _, cond := range []interface{}{
+		Callback{},
+		SuccessAction{Status: 302},
+		map[string]string{},
+		map[string]interface{}{"a": 1},
+		[]string{"starts-with", "content-type", "image/"},
+		[]string{"starts-with", "$content-type"},
+		[]string{"begins-with", "$key", "a"},
+		[]interface{}{"content-length-range", 10, 1},
+		[]interface{}{"content-length-range", "a", 1},
+		[]interface{}{"in", "$content-type", "image/png"},
+		[]interface{}{"in", "$content-type", []interface{}{"image/png", 1}},
+		"key",
+	} {
+		if err := ValidateConditions(cond); err == nil {
+			t.Errorf("%v should be invalid", cond)
+		}
+	}
+}
+
+func TestSuccessAction(t *testing.T) {
+	client := &Client{
+		AccessKeyId:     "foo",
+		AccessKeySecret: "bar",
+		Bucket:          "test",
+	}
+	fields := client.PostForm("a.txt", 0, 0, SuccessAction{
+		Redirect: "https://example.com/done",
+		Status:   201,
+	})
+	if fields["success_action_redirect"] != "https://example.com/done" || fields["success_action_status"] != "201" {
+		t.Error("wrong fields", fields)
+	}
+	policy, _ := base64.StdEncoding.DecodeString(fields["policy"])
+	if !strings.Contains(string(policy), `{"success_action_redirect":"https://example.com/done"}`) ||
+		!strings.Contains(string(policy), `{"success_action_status":"201"}`) {
+		t.Error("wrong policy", string(policy))
+	}
+}
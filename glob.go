@@ -0,0 +1,46 @@
+package ossslim
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+// Glob wraps GlobWithContext using context.Background.
+func (c *Client) Glob(pattern string) ([]string, error) {
+	return c.GlobWithContext(context.Background(), pattern)
+}
+
+// GlobWithContext returns keys of all files matching pattern, using the
+// syntax of path.Match, for example "images/*.png" or "logs/2020-0[1-3]/*".
+// As "*" does not match "/", files in subdirectories are not matched unless
+// the pattern has more "/". Files in the directory of the longest part of
+// pattern without special characters (like "logs" for "logs/2020-0[1-3]/*")
+// are listed recursively and filtered, so a pattern with a short static
+// prefix may take long for a bucket with many files.
+func (c *Client) GlobWithContext(ctx context.Context, pattern string) (keys []string, err error) {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if _, err = path.Match(pattern, ""); err != nil {
+		return
+	}
+	dir := pattern
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		dir = pattern[:i]
+	}
+	if i := strings.LastIndexByte(dir, '/'); i >= 0 {
+		dir = dir[:i]
+	} else {
+		dir = ""
+	}
+	var result ListResult
+	result, err = c.ListWithContext(ctx, dir, true)
+	if err != nil {
+		return
+	}
+	for _, file := range result.Files {
+		if matched, _ := path.Match(pattern, file.Name); matched {
+			keys = append(keys, file.Name)
+		}
+	}
+	return
+}
@@ -0,0 +1,27 @@
+package ossslim
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGlob(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if prefix := r.URL.Query().Get("prefix"); prefix != "logs/" {
+			t.Error("wrong prefix", prefix)
+		}
+		w.Write([]byte(listResponse("logs/", "logs/2020-01/a.log", "logs/2020-04/b.log",
+			"logs/2020-02/c/d.log", "logs/2020-03/e.txt")))
+	})
+	keys, err := client.Glob("/logs/2020-0[1-3]/*.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(keys, ",") != "logs/2020-01/a.log" {
+		t.Error("wrong keys", keys)
+	}
+	if _, err := client.Glob("logs/[a"); err == nil {
+		t.Error("bad pattern should return error")
+	}
+}
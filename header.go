@@ -0,0 +1,42 @@
+package ossslim
+
+import "net/http"
+
+// WithHeaders sets headers of the request, so new OSS features can be used
+// without a new option. The headers are merged when the request is sent,
+// replacing headers of the same names set by the client or other options,
+// regardless of the order of the options. x-oss-* headers are signed,
+// Content-Type and Content-MD5 are also signed. Date and Authorization are
+// ignored as they are computed when the request is sent.
+func WithHeaders(header http.Header) Option {
+	return func(req *Request) {
+		for key, values := range header {
+			if len(values) == 0 {
+				continue
+			}
+			if req.extraHeader == nil {
+				req.extraHeader = http.Header{}
+			}
+			key = http.CanonicalHeaderKey(key)
+			req.extraHeader[key] = append([]string(nil), values...)
+		}
+	}
+}
+
+// mergeHeaders merges headers of WithHeaders into the request.
+func (req *Request) mergeHeaders() {
+	for key, values := range req.extraHeader {
+		switch key {
+		case "Date", "Authorization":
+		case "Content-Type":
+			req.contentType = values[0]
+		case "Content-Md5":
+			req.contentMd5 = values[0]
+		default:
+			if req.header == nil {
+				req.header = http.Header{}
+			}
+			req.header[key] = append([]string(nil), values...)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package ossslim
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithHeaders(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Cache-Control") != "no-cache" || r.Header.Get("Content-Type") != "text/html" ||
+			r.Header.Get("x-oss-object-acl") != "private" || r.Header.Get("x-oss-forbid-overwrite") != "true" {
+			t.Error("wrong headers", r.Header)
+		}
+	})
+	req, err := client.UploadString("foo", "bar", "text/plain", WithHeaders(http.Header{
+		"cache-control":          {"no-cache"},
+		"Content-Type":           {"text/html"},
+		"X-Oss-Object-Acl":       {"private"},
+		"x-oss-forbid-overwrite": {"true"},
+		"Date":                   {"foo"},
+	}), WithACL(ACLPublicRead))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "text/html\n" + req.date + "\nx-oss-forbid-overwrite:true\nx-oss-object-acl:private\n/test/foo"
+	if !strings.HasSuffix(req.StringToSign, expected) {
+		t.Errorf("wrong string to sign %q", req.StringToSign)
+	}
+}
+
+func TestWithHeadersOverride(t *testing.T) {
+	var mtime string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mtime = r.Header.Get("x-oss-meta-mtime")
+	})
+	local := filepath.Join(t.TempDir(), "a.txt")
+	if err := ioutil.WriteFile(local, []byte("foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := client.UploadFile("a.txt", local, "", WithHeaders(http.Header{
+		"x-oss-meta-mtime": {"1"},
+	}), WithHeaders(http.Header{
+		"x-oss-meta-mtime": {"1600000000"},
+	}), WithPreserveMtime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mtime != "1600000000" {
+		t.Error("headers of WithHeaders should be used", mtime)
+	}
+}
@@ -0,0 +1,193 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type (
+	// ListV2Options are options of ListV2. If StartAfter is not empty, only
+	// files and directories after StartAfter in alphabetical order are
+	// listed. If FetchOwner is true, Owner of each file is returned.
+	ListV2Options struct {
+		StartAfter string
+		FetchOwner bool
+	}
+
+	fileListV2 struct {
+		Prefix                string
+		IsTruncated           bool
+		NextContinuationToken string
+		EncodingType          string
+		Files                 []File      `xml:"Contents"`
+		Directories           []Directory `xml:"CommonPrefixes"`
+	}
+)
+
+// Count returns the number of files in the result.
+func (result ListResult) Count() int {
+	return len(result.Files)
+}
+
+// TotalSize returns the total size of files in the result.
+func (result ListResult) TotalSize() (size int64) {
+	for _, file := range result.Files {
+		size += file.Size
+	}
+	return
+}
+
+// FilesByPrefix groups files in the result by their directories, the keys
+// of the returned map are directories with trailing slash (like "a/b/"), or
+// empty for files in the root directory. This is useful for building a tree
+// view of a recursive list.
+func (result ListResult) FilesByPrefix() map[string][]File {
+	groups := map[string][]File{}
+	for _, file := range result.Files {
+		dir := ""
+		if i := strings.LastIndexByte(file.Name, '/'); i >= 0 {
+			dir = file.Name[:i+1]
+		}
+		groups[dir] = append(groups[dir], file)
+	}
+	return groups
+}
+
+// LastModifiedTime parses LastModified of the file, it returns zero time if
+// LastModified is invalid.
+func (file File) LastModifiedTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, file.LastModified)
+	return t
+}
+
+// ListPages wraps ListPagesWithContext using context.Background.
+func (c *Client) ListPages(prefix string, recursive bool, fn func(ListResult) error) error {
+	return c.ListPagesWithContext(context.Background(), prefix, recursive, fn)
+}
+
+// ListPagesWithContext is like ListWithContext but calls fn with each page of
+// at most 1000 files as soon as it is received, instead of returning all
+// files at the end, so large directories can be processed incrementally
+// without keeping all files in memory. Listing stops if fn returns an error,
+// which is returned.
+func (c *Client) ListPagesWithContext(ctx context.Context, prefix string, recursive bool, fn func(ListResult) error) error {
+	req := &Request{
+		client: c,
+		ctx:    ctx,
+	}
+	return req.list(prefix, "", recursive, fn)
+}
+
+// ListSince wraps ListSinceWithContext using context.Background.
+func (c *Client) ListSince(prefix string, since time.Time) ([]File, error) {
+	return c.ListSinceWithContext(context.Background(), prefix, since)
+}
+
+// ListSinceWithContext returns files in directory prefix and its
+// subdirectories which are modified after since. As OSS can not filter files
+// by time, all files in the directory are listed.
+func (c *Client) ListSinceWithContext(ctx context.Context, prefix string, since time.Time) (files []File, err error) {
+	var result ListResult
+	result, err = c.ListWithContext(ctx, prefix, true)
+	if err != nil {
+		return
+	}
+	for _, file := range result.Files {
+		if file.LastModifiedTime().After(since) {
+			files = append(files, file)
+		}
+	}
+	return
+}
+
+// decodeListKeys decodes names of files and directories and other values of
+// a list response requested with encoding-type=url, in which keys with
+// control characters (like newlines) are URL-encoded.
+func decodeListKeys(files []File, dirs []Directory, values ...*string) (err error) {
+	for i := range files {
+		if files[i].Name, err = url.QueryUnescape(files[i].Name); err != nil {
+			return
+		}
+	}
+	for i := range dirs {
+		if dirs[i].Name, err = url.QueryUnescape(dirs[i].Name); err != nil {
+			return
+		}
+	}
+	for _, value := range values {
+		if *value, err = url.QueryUnescape(*value); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ListV2 wraps ListV2WithContext using context.Background.
+func (c *Client) ListV2(prefix string, recursive bool, options ListV2Options) (ListResult, error) {
+	return c.ListV2WithContext(context.Background(), prefix, recursive, options)
+}
+
+// ListV2WithContext is like ListWithContext but uses ListObjectsV2, which
+// pages through the results with continuation tokens instead of markers.
+// Unlike List, Owner of files is empty unless FetchOwner is set.
+func (c *Client) ListV2WithContext(ctx context.Context, prefix string, recursive bool, options ListV2Options) (result ListResult, err error) {
+	prefix = listPrefix(prefix)
+	token := ""
+	for {
+		queries := url.Values{}
+		queries.Set("list-type", "2")
+		queries.Set("max-keys", "1000")
+		queries.Set("prefix", prefix)
+		queries.Set("encoding-type", "url")
+		if !recursive {
+			queries.Set("delimiter", "/")
+		}
+		if options.StartAfter != "" {
+			queries.Set("start-after", options.StartAfter)
+		}
+		if options.FetchOwner {
+			queries.Set("fetch-owner", "true")
+		}
+		if token != "" {
+			queries.Set("continuation-token", token)
+		}
+		var response bytes.Buffer
+		req := &Request{
+			client:   c,
+			ctx:      ctx,
+			remote:   "/",
+			method:   "GET",
+			respBody: &response,
+			queries:  queries,
+		}
+		if err = req.do(); err != nil {
+			return
+		}
+		var list fileListV2
+		if err = xml.NewDecoder(&response).Decode(&list); err != nil {
+			return
+		}
+		if list.EncodingType == "url" {
+			if err = decodeListKeys(list.Files, list.Directories, &list.Prefix); err != nil {
+				return
+			}
+		}
+		for _, file := range list.Files {
+			if !recursive && file.Name == prefix {
+				// skip the directory marker object itself
+				continue
+			}
+			result.Files = append(result.Files, file)
+		}
+		result.Dirs = append(result.Dirs, list.Directories...)
+		result.Prefix = list.Prefix
+		if !list.IsTruncated || list.NextContinuationToken == "" {
+			return
+		}
+		token = list.NextContinuationToken
+	}
+}
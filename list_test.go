@@ -0,0 +1,247 @@
+package ossslim
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListResultHelpers(t *testing.T) {
+	result := ListResult{
+		Files: []File{
+			{Name: "a.txt", Size: 1},
+			{Name: "b/c.txt", Size: 10},
+			{Name: "b/d.txt", Size: 100},
+			{Name: "b/e/f.txt", Size: 1000},
+		},
+	}
+	if result.Count() != 4 {
+		t.Error("wrong count", result.Count())
+	}
+	if result.TotalSize() != 1111 {
+		t.Error("wrong total size", result.TotalSize())
+	}
+	groups := result.FilesByPrefix()
+	if len(groups) != 3 || len(groups[""]) != 1 || len(groups["b/"]) != 2 || groups["b/e/"][0].Name != "b/e/f.txt" {
+		t.Error("wrong groups", groups)
+	}
+}
+
+func TestListSince(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult>
+  <IsTruncated>false</IsTruncated>
+  <Contents><Key>a.txt</Key><LastModified>2020-12-29T13:05:20.000Z</LastModified></Contents>
+  <Contents><Key>b.txt</Key><LastModified>2020-12-30T13:05:20.000Z</LastModified></Contents>
+</ListBucketResult>`))
+	})
+	files, err := client.ListSince("", time.Date(2020, 12, 29, 13, 5, 20, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "b.txt" {
+		t.Error("wrong files", files)
+	}
+	if !files[0].LastModifiedTime().Equal(time.Date(2020, 12, 30, 13, 5, 20, 0, time.UTC)) {
+		t.Error("wrong time", files[0].LastModifiedTime())
+	}
+}
+
+func TestListV2(t *testing.T) {
+	var queries []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("continuation-token") == "" {
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult>
+  <Prefix>dir/</Prefix>
+  <IsTruncated>true</IsTruncated>
+  <NextContinuationToken>CgJiYw--</NextContinuationToken>
+  <Contents><Key>dir/</Key></Contents>
+  <Contents><Key>dir/b</Key><Owner><ID>1</ID><DisplayName>foo</DisplayName></Owner></Contents>
+</ListBucketResult>`))
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult>
+  <Prefix>dir/</Prefix>
+  <IsTruncated>false</IsTruncated>
+  <Contents><Key>dir/c</Key></Contents>
+  <CommonPrefixes><Prefix>dir/d/</Prefix></CommonPrefixes>
+</ListBucketResult>`))
+	})
+	result, err := client.ListV2("/dir/", false, ListV2Options{StartAfter: "dir/a", FetchOwner: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 2 || result.Files[0].Name != "dir/b" || result.Files[0].Owner.DisplayName != "foo" ||
+		result.Files[1].Name != "dir/c" || len(result.Dirs) != 1 || result.Dirs[0].Name != "dir/d/" ||
+		result.Prefix != "dir/" {
+		t.Error("wrong result", result)
+	}
+	expected := []string{
+		"delimiter=%2F&encoding-type=url&fetch-owner=true&list-type=2&max-keys=1000&prefix=dir%2F&start-after=dir%2Fa",
+		"continuation-token=CgJiYw--&delimiter=%2F&encoding-type=url&fetch-owner=true&list-type=2&max-keys=1000&prefix=dir%2F&start-after=dir%2Fa",
+	}
+	if len(queries) != 2 || queries[0] != expected[0] || queries[1] != expected[1] {
+		t.Error("wrong queries", queries)
+	}
+}
+
+// newPagingServer returns a client of a server which lists keys two at a
+// time, like OSS, using markers or continuation tokens.
+func newPagingServer(t *testing.T, keys []string) (*Client, *int) {
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 100 {
+			t.Error("too many requests")
+			w.WriteHeader(400)
+			return
+		}
+		query := r.URL.Query()
+		after := query.Get("marker")
+		if query.Get("list-type") == "2" {
+			after = query.Get("start-after")
+			if token := query.Get("continuation-token"); token != "" {
+				b, err := base64.StdEncoding.DecodeString(token)
+				if err != nil {
+					t.Error(err)
+				}
+				after = string(b)
+			}
+		}
+		var page []string
+		for _, key := range keys {
+			if key > after && len(page) < 2 {
+				page = append(page, key)
+			}
+		}
+		truncated := len(page) > 0 && page[len(page)-1] != keys[len(keys)-1]
+		var b strings.Builder
+		fmt.Fprintf(&b, "<ListBucketResult><IsTruncated>%t</IsTruncated>", truncated)
+		if truncated {
+			last := page[len(page)-1]
+			b.WriteString("<NextMarker>")
+			xml.EscapeText(&b, []byte(last))
+			b.WriteString("</NextMarker><NextContinuationToken>")
+			b.WriteString(base64.StdEncoding.EncodeToString([]byte(last)))
+			b.WriteString("</NextContinuationToken>")
+		}
+		for _, key := range page {
+			b.WriteString("<Contents><Key>")
+			xml.EscapeText(&b, []byte(key))
+			b.WriteString("</Key></Contents>")
+		}
+		b.WriteString("</ListBucketResult>")
+		w.Write([]byte(b.String()))
+	})
+	return client, &requests
+}
+
+func TestListSpecialKeys(t *testing.T) {
+	keys := []string{
+		"a b", "a&b", "a+b", "a+b+c", "a/b", "a=b?c#d", "a%20b", "a<b>",
+		"文件", "文件/目录 1", "😀",
+	}
+	sort.Strings(keys)
+	client, requests := newPagingServer(t, keys)
+	result, err := client.List("", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range result.Files {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, "\n") != strings.Join(keys, "\n") || *requests != 6 {
+		t.Error("wrong keys", *requests, names)
+	}
+
+	*requests = 0
+	result, err = client.ListV2("", true, ListV2Options{StartAfter: "a b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names = nil
+	for _, file := range result.Files {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, "\n") != strings.Join(keys[1:], "\n") || *requests != 5 {
+		t.Error("wrong keys", *requests, names)
+	}
+}
+
+func TestListEncodingType(t *testing.T) {
+	var markers []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("encoding-type") != "url" {
+			t.Error("encoding-type should be url")
+		}
+		markers = append(markers, query.Get("marker"))
+		if query.Get("marker") == "" && query.Get("list-type") == "" {
+			w.Write([]byte(`<ListBucketResult><EncodingType>url</EncodingType><Prefix>dir%0A/</Prefix>
+<IsTruncated>true</IsTruncated><NextMarker>dir%0A/a%0Ab</NextMarker>
+<Contents><Key>dir%0A/a%0Ab</Key></Contents></ListBucketResult>`))
+			return
+		}
+		w.Write([]byte(`<ListBucketResult><EncodingType>url</EncodingType><Prefix>dir%0A/</Prefix>
+<IsTruncated>false</IsTruncated>
+<Contents><Key>dir%0A/c%09d+e</Key></Contents>
+<CommonPrefixes><Prefix>dir%0A/f%0D/</Prefix></CommonPrefixes></ListBucketResult>`))
+	})
+	result, err := client.List("dir\n", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 2 || result.Files[0].Name != "dir\n/a\nb" || result.Files[1].Name != "dir\n/c\td e" ||
+		len(result.Dirs) != 1 || result.Dirs[0].Name != "dir\n/f\r/" || result.Prefix != "dir\n/" {
+		t.Errorf("wrong result %q", result)
+	}
+	if len(markers) != 2 || markers[1] != "dir\n/a\nb" {
+		t.Errorf("wrong markers %q", markers)
+	}
+	result, err = client.ListV2("dir\n", false, ListV2Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Name != "dir\n/c\td e" || result.Dirs[0].Name != "dir\n/f\r/" {
+		t.Errorf("wrong result %q", result)
+	}
+}
+
+func TestListPages(t *testing.T) {
+	client, requests := newPagingServer(t, []string{"a", "b", "c", "d", "e"})
+	var pages []string
+	err := client.ListPages("", true, func(page ListResult) error {
+		var names []string
+		for _, file := range page.Files {
+			names = append(names, file.Name)
+		}
+		pages = append(pages, strings.Join(names, ","))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(pages, " ") != "a,b c,d e" || *requests != 3 {
+		t.Error("wrong pages", *requests, pages)
+	}
+
+	*requests = 0
+	stop := errors.New("stop")
+	err = client.ListPages("", true, func(page ListResult) error {
+		return stop
+	})
+	if err != stop || *requests != 1 {
+		t.Error("listing should stop", *requests, err)
+	}
+}
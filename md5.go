@@ -0,0 +1,87 @@
+package ossslim
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// AutoMD5 computes MD5 of the request body if it is not provided and the body
+// is an io.ReadSeeker (like *os.File, *bytes.Reader and *strings.Reader), so
+// OSS can verify the integrity of the uploaded file. The body is read once
+// and seeked back before it is sent. Other bodies are uploaded without MD5.
+func AutoMD5() Option {
+	return func(req *Request) {
+		req.autoMd5 = true
+	}
+}
+
+func (req *Request) computeMd5() error {
+	seeker, ok := req.reqBody.(io.ReadSeeker)
+	if !ok {
+		return nil
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	md5sum := md5.New()
+	size, err := io.Copy(md5sum, seeker)
+	if err != nil {
+		return err
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+	req.contentMd5 = base64.StdEncoding.EncodeToString(md5sum.Sum(nil))
+	if req.reqBodyLength == 0 {
+		req.reqBodyLength = size
+	}
+	return nil
+}
+
+// ExpectedETag returns the ETag OSS would return for local file, in the same
+// format as ETag of File, so it can be compared without requesting OSS. If
+// partSize is not greater than 0, the file is assumed to be uploaded in one
+// request (like Upload), its ETag is the MD5 of the file in upper case hex.
+// Otherwise, it is assumed to be uploaded by multipart upload in parts of
+// partSize bytes, its ETag is the MD5 of the concatenated binary MD5 of all
+// parts, followed by "-" and the number of parts, so it depends on the part
+// size. ETags of files encrypted with KMS are not predictable.
+func ExpectedETag(localPath string, partSize int64) (string, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if partSize <= 0 {
+		md5sum := md5.New()
+		if _, err := io.Copy(md5sum, file); err != nil {
+			return "", err
+		}
+		return `"` + strings.ToUpper(hex.EncodeToString(md5sum.Sum(nil))) + `"`, nil
+	}
+	var sums []byte
+	parts := 0
+	for {
+		md5sum := md5.New()
+		n, err := io.CopyN(md5sum, file, partSize)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if n == 0 && parts > 0 {
+			break
+		}
+		sums = md5sum.Sum(sums)
+		parts++
+		if n < partSize {
+			break
+		}
+	}
+	sum := md5.Sum(sums)
+	return `"` + strings.ToUpper(hex.EncodeToString(sum[:])) + "-" + strconv.Itoa(parts) + `"`, nil
+}
@@ -0,0 +1,76 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// MediaInfo is the info of a video or audio file returned by MediaInfo.
+// Duration is in seconds. Fields contains all fields returned by OSS, like
+// VideoStreams and AudioStreams.
+type MediaInfo struct {
+	Duration   float64
+	FormatName string
+	Size       int64
+	Fields     map[string]interface{}
+}
+
+// VideoSnapshot wraps VideoSnapshotWithContext using context.Background.
+func (c *Client) VideoSnapshot(remote string, timeMs int, w io.Writer) (*Request, error) {
+	return c.VideoSnapshotWithContext(context.Background(), remote, timeMs, w)
+}
+
+// VideoSnapshotWithContext writes a JPEG image of the frame at timeMs
+// milliseconds of remote video to w, for example a poster frame, without
+// downloading the video.
+func (c *Client) VideoSnapshotWithContext(ctx context.Context, remote string, timeMs int, w io.Writer) (*Request, error) {
+	req := &Request{
+		client:   c,
+		ctx:      ctx,
+		remote:   remote,
+		method:   "GET",
+		respBody: w,
+		queries:  url.Values{},
+	}
+	req.queries.Set("x-oss-process", "video/snapshot,t_"+strconv.Itoa(timeMs)+",f_jpg")
+	return req, req.do()
+}
+
+// MediaInfo wraps MediaInfoWithContext using context.Background.
+func (c *Client) MediaInfo(remote string) (*MediaInfo, *Request, error) {
+	return c.MediaInfoWithContext(context.Background(), remote)
+}
+
+// MediaInfoWithContext returns the info of remote video or audio file. The
+// bucket must be bound to an IMM (Intelligent Media Management) project,
+// otherwise an *Error is returned.
+func (c *Client) MediaInfoWithContext(ctx context.Context, remote string) (info *MediaInfo, req *Request, err error) {
+	var response bytes.Buffer
+	req = &Request{
+		client:   c,
+		ctx:      ctx,
+		remote:   remote,
+		method:   "GET",
+		respBody: &response,
+		queries:  url.Values{},
+	}
+	req.queries.Set("x-oss-process", "video/info")
+	if err = req.do(); err != nil {
+		return
+	}
+	var fields map[string]interface{}
+	if err = json.NewDecoder(&response).Decode(&fields); err != nil {
+		return
+	}
+	info = &MediaInfo{Fields: fields}
+	info.Duration, _ = fields["Duration"].(float64)
+	info.FormatName, _ = fields["FormatName"].(string)
+	if size, ok := fields["Size"].(float64); ok {
+		info.Size = int64(size)
+	}
+	return
+}
@@ -0,0 +1,41 @@
+package ossslim
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestVideoSnapshot(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.Query().Get("x-oss-process"); p != "video/snapshot,t_1500,f_jpg" {
+			t.Error("wrong process", p)
+		}
+		w.Write([]byte("jpeg"))
+	})
+	var buf bytes.Buffer
+	if _, err := client.VideoSnapshot("foo.mp4", 1500, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "jpeg" {
+		t.Error("wrong snapshot", buf.String())
+	}
+}
+
+func TestMediaInfo(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.Query().Get("x-oss-process"); p != "video/info" {
+			t.Error("wrong process", p)
+		}
+		w.Write([]byte(`{"Duration": 9.56, "FormatName": "mov,mp4,m4a,3gp,3g2,mj2", "Size": 3253632,
+"VideoStreams": [{"Width": 1280, "Height": 720}]}`))
+	})
+	info, _, err := client.MediaInfo("foo.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Duration != 9.56 || info.FormatName != "mov,mp4,m4a,3gp,3g2,mj2" || info.Size != 3253632 ||
+		len(info.Fields["VideoStreams"].([]interface{})) != 1 {
+		t.Error("wrong media info", info)
+	}
+}
@@ -0,0 +1,17 @@
+package ossslim
+
+import "strings"
+
+const metaPrefix = "x-oss-meta-"
+
+// WithMeta sets user metadata of the uploaded file, each key-value pair is
+// sent as a x-oss-meta-<key> header. Keys are case-insensitive and are
+// returned in lower case in FileInfo.Meta of Stat. Total size of user
+// metadata can not exceed 8 KB.
+func WithMeta(meta map[string]string) Option {
+	return func(req *Request) {
+		for key, value := range meta {
+			req.setHeader(metaPrefix+strings.ToLower(key), value)
+		}
+	}
+}
@@ -1,8 +1,11 @@
-package main
+package ossslim
 
 import "strings"
 
-func contentTypeForExtension(ext string) string {
+// ContentTypeForExtension returns the MIME type for file extension ext (with
+// or without the leading dot). "application/octet-stream" is returned for
+// unknown extensions.
+func ContentTypeForExtension(ext string) string {
 	ext = strings.TrimPrefix(ext, ".")
 	switch ext {
 	case "html", "htm", "shtml":
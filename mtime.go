@@ -0,0 +1,50 @@
+package ossslim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metadata key of the modification time of the local file
+const mtimeMetaKey = "mtime"
+
+// WithPreserveMtime makes UploadFile record the modification time of the
+// local file as x-oss-meta-mtime, and makes DownloadFile set the
+// modification time of the downloaded file to it, as LastModified of a
+// remote file is the upload time. The time is stored as seconds since the
+// Unix epoch with nanoseconds (like "1600000000.123456789"), the same as
+// rclone. If the remote file has no valid x-oss-meta-mtime, the downloaded
+// file is not changed. It has no effect on other methods.
+func WithPreserveMtime() Option {
+	return func(req *Request) {
+		req.preserveMtime = true
+	}
+}
+
+func formatMtime(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+// parseMtime parses time formatted by formatMtime, fraction is optional.
+func parseMtime(value string) (time.Time, error) {
+	secs, frac := value, ""
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		secs, frac = value[:i], value[i+1:]
+	}
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var nsec int64
+	if frac != "" {
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		if nsec, err = strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Unix(sec, nsec), nil
+}
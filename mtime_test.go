@@ -0,0 +1,68 @@
+package ossslim
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPreserveMtime(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "a.txt")
+	if err := ioutil.WriteFile(local, []byte("foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Unix(1600000000, 123456789)
+	if err := os.Chtimes(local, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	var stored string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			stored = r.Header.Get("x-oss-meta-mtime")
+			return
+		}
+		w.Header().Set("x-oss-meta-mtime", stored)
+		w.Write([]byte("foo"))
+	})
+	if _, err := client.UploadFile("a.txt", local, ""); err != nil {
+		t.Fatal(err)
+	}
+	if stored != "" {
+		t.Error("mtime should not be stored without option", stored)
+	}
+	if _, err := client.UploadFile("a.txt", local, "", WithPreserveMtime()); err != nil {
+		t.Fatal(err)
+	}
+	if stored != "1600000000.123456789" {
+		t.Error("wrong mtime", stored)
+	}
+	downloaded := filepath.Join(dir, "b.txt")
+	if _, err := client.DownloadFile("a.txt", downloaded, WithPreserveMtime()); err != nil {
+		t.Fatal(err)
+	}
+	if stat, err := os.Stat(downloaded); err != nil || !stat.ModTime().Equal(mtime) {
+		t.Error("wrong mtime of downloaded file", stat.ModTime(), err)
+	}
+	stored = "1600000000"
+	if _, err := client.DownloadFile("a.txt", downloaded, WithPreserveMtime()); err != nil {
+		t.Fatal(err)
+	}
+	if stat, err := os.Stat(downloaded); err != nil || !stat.ModTime().Equal(time.Unix(1600000000, 0)) {
+		t.Error("wrong mtime of downloaded file", stat.ModTime(), err)
+	}
+	for value, expected := range map[string]time.Time{
+		"1600000000.5":          time.Unix(1600000000, 500000000),
+		"1600000000.1234567891": time.Unix(1600000000, 123456789),
+	} {
+		if parsed, err := parseMtime(value); err != nil || !parsed.Equal(expected) {
+			t.Error("wrong mtime", value, parsed, err)
+		}
+	}
+	if _, err := parseMtime("foo"); err == nil {
+		t.Error("invalid mtime should return error")
+	}
+}
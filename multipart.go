@@ -0,0 +1,281 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Part is an uploaded part of a multipart upload. LastModified and Size
+	// are only available in ListParts results.
+	Part struct {
+		PartNumber   int
+		ETag         string
+		LastModified string
+		Size         int64
+	}
+
+	// MultipartUpload is an initiated but not yet completed or aborted
+	// multipart upload.
+	MultipartUpload struct {
+		Key       string
+		UploadId  string
+		Initiated string
+	}
+
+	initiateMultipartUploadResult struct {
+		Bucket   string
+		Key      string
+		UploadId string
+	}
+
+	completePart struct {
+		PartNumber int
+		ETag       string
+	}
+
+	completeMultipartUpload struct {
+		XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+		Parts   []completePart `xml:"Part"`
+	}
+
+	listMultipartUploadsResult struct {
+		NextKeyMarker      string
+		NextUploadIdMarker string
+		IsTruncated        bool
+		Uploads            []MultipartUpload `xml:"Upload"`
+	}
+
+	listPartsResult struct {
+		NextPartNumberMarker string
+		IsTruncated          bool
+		Parts                []Part `xml:"Part"`
+	}
+)
+
+// InitiateMultipartUpload wraps InitiateMultipartUploadWithContext using
+// context.Background.
+func (c *Client) InitiateMultipartUpload(remote, contentType string) (string, error) {
+	return c.InitiateMultipartUploadWithContext(context.Background(), remote, contentType)
+}
+
+// InitiateMultipartUploadWithContext initiates a multipart upload to remote
+// path and returns the upload ID, which should be used in UploadPart,
+// CompleteMultipartUpload and AbortMultipartUpload. If contentType is empty,
+// "application/octet-stream" will be used.
+func (c *Client) InitiateMultipartUploadWithContext(ctx context.Context, remote, contentType string) (string, error) {
+	var response bytes.Buffer
+	req := &Request{
+		client:      c,
+		ctx:         ctx,
+		remote:      remote,
+		method:      "POST",
+		contentType: contentType,
+		respBody:    &response,
+		queries:     url.Values{"uploads": []string{""}},
+	}
+	if err := req.do(); err != nil {
+		return "", err
+	}
+	var result initiateMultipartUploadResult
+	if err := xml.NewDecoder(&response).Decode(&result); err != nil {
+		return "", err
+	}
+	return result.UploadId, nil
+}
+
+// UploadPart wraps UploadPartWithContext using context.Background.
+func (c *Client) UploadPart(remote, uploadId string, partNumber int, reqBody io.Reader) (string, error) {
+	return c.UploadPartWithContext(context.Background(), remote, uploadId, partNumber, reqBody)
+}
+
+// UploadPartWithContext uploads reqBody as part partNumber (1 to 10000) of
+// the multipart upload and returns the ETag of the part. Every part except
+// the last one must be at least 100 KB.
+func (c *Client) UploadPartWithContext(ctx context.Context, remote, uploadId string, partNumber int, reqBody io.Reader) (string, error) {
+	req := &Request{
+		client:  c,
+		ctx:     ctx,
+		remote:  remote,
+		method:  "PUT",
+		reqBody: reqBody,
+		queries: url.Values{
+			"partNumber": []string{strconv.Itoa(partNumber)},
+			"uploadId":   []string{uploadId},
+		},
+	}
+	_, err := c.upload(req, nil)
+	return req.ETag, err
+}
+
+// CompleteMultipartUpload wraps CompleteMultipartUploadWithContext using
+// context.Background.
+func (c *Client) CompleteMultipartUpload(remote, uploadId string, parts []Part) error {
+	return c.CompleteMultipartUploadWithContext(context.Background(), remote, uploadId, parts)
+}
+
+// CompleteMultipartUploadWithContext completes the multipart upload with
+// parts, which should be sorted by part number. Once completed, the parts are
+// combined into the remote file.
+func (c *Client) CompleteMultipartUploadWithContext(ctx context.Context, remote, uploadId string, parts []Part) error {
+	var reqBody bytes.Buffer
+	reqBody.WriteString(xml.Header)
+	completeParts := []completePart{}
+	for _, part := range parts {
+		completeParts = append(completeParts, completePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		})
+	}
+	if err := xml.NewEncoder(&reqBody).Encode(completeMultipartUpload{
+		Parts: completeParts,
+	}); err != nil {
+		return err
+	}
+	md5sum := md5.Sum(reqBody.Bytes())
+	req := &Request{
+		client:     c,
+		ctx:        ctx,
+		remote:     remote,
+		method:     "POST",
+		reqBody:    bytes.NewReader(reqBody.Bytes()),
+		contentMd5: base64.StdEncoding.EncodeToString(md5sum[:]),
+		queries:    url.Values{"uploadId": []string{uploadId}},
+	}
+	return req.do()
+}
+
+// AbortMultipartUpload wraps AbortMultipartUploadWithContext using
+// context.Background.
+func (c *Client) AbortMultipartUpload(remote, uploadId string) error {
+	return c.AbortMultipartUploadWithContext(context.Background(), remote, uploadId)
+}
+
+// AbortMultipartUploadWithContext aborts the multipart upload and removes
+// its uploaded parts.
+func (c *Client) AbortMultipartUploadWithContext(ctx context.Context, remote, uploadId string) error {
+	req := &Request{
+		client:  c,
+		ctx:     ctx,
+		remote:  remote,
+		method:  "DELETE",
+		queries: url.Values{"uploadId": []string{uploadId}},
+	}
+	return req.do()
+}
+
+// ListMultipartUploads wraps ListMultipartUploadsWithContext using
+// context.Background.
+func (c *Client) ListMultipartUploads(prefix string) ([]MultipartUpload, error) {
+	return c.ListMultipartUploadsWithContext(context.Background(), prefix)
+}
+
+// ListMultipartUploadsWithContext lists all initiated but not yet completed
+// or aborted multipart uploads whose keys start with prefix.
+func (c *Client) ListMultipartUploadsWithContext(ctx context.Context, prefix string) (uploads []MultipartUpload, err error) {
+	var keyMarker, uploadIdMarker string
+	for {
+		var response bytes.Buffer
+		req := &Request{
+			client:   c,
+			ctx:      ctx,
+			remote:   "/",
+			method:   "GET",
+			respBody: &response,
+			queries: url.Values{
+				"uploads":          []string{""},
+				"max-uploads":      []string{"1000"},
+				"prefix":           []string{strings.TrimPrefix(prefix, "/")},
+				"key-marker":       []string{keyMarker},
+				"upload-id-marker": []string{uploadIdMarker},
+			},
+		}
+		if err = req.do(); err != nil {
+			return
+		}
+		var result listMultipartUploadsResult
+		if err = xml.NewDecoder(&response).Decode(&result); err != nil {
+			return
+		}
+		uploads = append(uploads, result.Uploads...)
+		if !result.IsTruncated {
+			return
+		}
+		keyMarker, uploadIdMarker = result.NextKeyMarker, result.NextUploadIdMarker
+	}
+}
+
+// ListParts wraps ListPartsWithContext using context.Background.
+func (c *Client) ListParts(remote, uploadId string) ([]Part, error) {
+	return c.ListPartsWithContext(context.Background(), remote, uploadId)
+}
+
+// ListPartsWithContext lists all uploaded parts of the multipart upload.
+func (c *Client) ListPartsWithContext(ctx context.Context, remote, uploadId string) (parts []Part, err error) {
+	var partNumberMarker string
+	for {
+		var response bytes.Buffer
+		req := &Request{
+			client:   c,
+			ctx:      ctx,
+			remote:   remote,
+			method:   "GET",
+			respBody: &response,
+			queries: url.Values{
+				"uploadId":           []string{uploadId},
+				"max-parts":          []string{"1000"},
+				"part-number-marker": []string{partNumberMarker},
+			},
+		}
+		if err = req.do(); err != nil {
+			return
+		}
+		var result listPartsResult
+		if err = xml.NewDecoder(&response).Decode(&result); err != nil {
+			return
+		}
+		parts = append(parts, result.Parts...)
+		if !result.IsTruncated {
+			return
+		}
+		partNumberMarker = result.NextPartNumberMarker
+	}
+}
+
+// AbortMultipartUploadsOlderThan wraps
+// AbortMultipartUploadsOlderThanWithContext using context.Background.
+func (c *Client) AbortMultipartUploadsOlderThan(prefix string, age time.Duration) ([]MultipartUpload, error) {
+	return c.AbortMultipartUploadsOlderThanWithContext(context.Background(), prefix, age)
+}
+
+// AbortMultipartUploadsOlderThanWithContext aborts multipart uploads under
+// prefix that were initiated more than age ago, returns the aborted uploads.
+// This can be used in scheduled jobs to clean up parts of interrupted
+// uploads.
+func (c *Client) AbortMultipartUploadsOlderThanWithContext(ctx context.Context, prefix string, age time.Duration) (aborted []MultipartUpload, err error) {
+	var uploads []MultipartUpload
+	uploads, err = c.ListMultipartUploadsWithContext(ctx, prefix)
+	if err != nil {
+		return
+	}
+	deadline := time.Now().Add(-age)
+	for _, upload := range uploads {
+		initiated, perr := time.Parse(time.RFC3339, upload.Initiated)
+		if perr != nil || initiated.After(deadline) {
+			continue
+		}
+		if err = c.AbortMultipartUploadWithContext(ctx, upload.Key, upload.UploadId); err != nil {
+			return
+		}
+		aborted = append(aborted, upload)
+	}
+	return
+}
@@ -0,0 +1,121 @@
+package ossslim
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMultipartUpload(t *testing.T) {
+	var completed string
+	aborted := false
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.Method == "POST" && q["uploads"] != nil:
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<InitiateMultipartUploadResult>
+  <Bucket>test</Bucket>
+  <Key>foo</Key>
+  <UploadId>0004B9894A22E5B1888A1E29F823</UploadId>
+</InitiateMultipartUploadResult>`))
+		case r.Method == "PUT" && q.Get("uploadId") == "0004B9894A22E5B1888A1E29F823":
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("ETag", `"`+string(body)+q.Get("partNumber")+`"`)
+		case r.Method == "POST" && q.Get("uploadId") == "0004B9894A22E5B1888A1E29F823":
+			body, _ := ioutil.ReadAll(r.Body)
+			completed = string(body)
+		case r.Method == "DELETE" && q.Get("uploadId") == "0004B9894A22E5B1888A1E29F823":
+			aborted = true
+			w.WriteHeader(204)
+		default:
+			t.Error("unexpected request", r.Method, r.URL)
+			w.WriteHeader(400)
+		}
+	})
+	uploadId, err := client.InitiateMultipartUpload("foo", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploadId != "0004B9894A22E5B1888A1E29F823" {
+		t.Fatal("wrong upload id", uploadId)
+	}
+	var parts []Part
+	for i, content := range []string{"a", "b"} {
+		etag, err := client.UploadPart("foo", uploadId, i+1, strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, Part{PartNumber: i + 1, ETag: etag})
+	}
+	if err := client.CompleteMultipartUpload("foo", uploadId, parts); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<CompleteMultipartUpload><Part><PartNumber>1</PartNumber><ETag>&#34;a1&#34;</ETag></Part>` +
+		`<Part><PartNumber>2</PartNumber><ETag>&#34;b2&#34;</ETag></Part></CompleteMultipartUpload>`
+	if !strings.HasSuffix(completed, expected) {
+		t.Fatal("wrong complete request body", completed)
+	}
+	if err := client.AbortMultipartUpload("foo", uploadId); err != nil {
+		t.Fatal(err)
+	}
+	if !aborted {
+		t.Fatal("should be aborted")
+	}
+}
+
+func TestAbortMultipartUploadsOlderThan(t *testing.T) {
+	var aborted []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.Method == "GET" && q["uploads"] != nil:
+			if q.Get("prefix") != "tmp/" {
+				t.Error("wrong prefix", q.Get("prefix"))
+			}
+			if q.Get("key-marker") == "" {
+				w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListMultipartUploadsResult>
+  <Bucket>test</Bucket>
+  <NextKeyMarker>tmp/a</NextKeyMarker>
+  <NextUploadIdMarker>1</NextUploadIdMarker>
+  <IsTruncated>true</IsTruncated>
+  <Upload>
+    <Key>tmp/a</Key>
+    <UploadId>1</UploadId>
+    <Initiated>2012-02-23T04:18:23.000Z</Initiated>
+  </Upload>
+</ListMultipartUploadsResult>`))
+				return
+			}
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListMultipartUploadsResult>
+  <Bucket>test</Bucket>
+  <IsTruncated>false</IsTruncated>
+  <Upload>
+    <Key>tmp/b</Key>
+    <UploadId>2</UploadId>
+    <Initiated>` + time.Now().UTC().Format(time.RFC3339) + `</Initiated>
+  </Upload>
+</ListMultipartUploadsResult>`))
+		case r.Method == "DELETE":
+			aborted = append(aborted, r.URL.Path+"?"+q.Get("uploadId"))
+			w.WriteHeader(204)
+		default:
+			t.Error("unexpected request", r.Method, r.URL)
+			w.WriteHeader(400)
+		}
+	})
+	uploads, err := client.AbortMultipartUploadsOlderThan("tmp/", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 1 || uploads[0].Key != "tmp/a" {
+		t.Fatal("wrong aborted uploads", uploads)
+	}
+	if len(aborted) != 1 || aborted[0] != "/tmp/a?1" {
+		t.Fatal("wrong aborted uploads", aborted)
+	}
+}
@@ -0,0 +1,26 @@
+package ossslim
+
+// Storage classes of files and buckets. Files of IA (infrequent access)
+// storage class are cheaper to store but are charged for retrieval,
+// archived files must be restored before they can be downloaded.
+const (
+	StorageStandard    = "Standard"
+	StorageIA          = "IA"
+	StorageArchive     = "Archive"
+	StorageColdArchive = "ColdArchive"
+)
+
+// WithACL sets the ACL (like ACLPublicRead) of the uploaded or copied file.
+func WithACL(acl string) Option {
+	return func(req *Request) {
+		req.setHeader("x-oss-object-acl", acl)
+	}
+}
+
+// WithStorageClass sets the storage class (like StorageIA) of the uploaded
+// or copied file, the storage class of the bucket is used by default.
+func WithStorageClass(storageClass string) Option {
+	return func(req *Request) {
+		req.setHeader("x-oss-storage-class", storageClass)
+	}
+}
@@ -0,0 +1,26 @@
+package ossslim
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestACLAndStorageClass(t *testing.T) {
+	var headers []http.Header
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+		if r.Header.Get("x-oss-copy-source") != "" {
+			w.Write([]byte(`<CopyObjectResult><ETag>"foo"</ETag></CopyObjectResult>`))
+		}
+	})
+	if _, err := client.UploadString("a", "a", "", WithACL(ACLPublicRead), WithStorageClass(StorageIA)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Copy("a", "b", WithACL(ACLDefault), WithStorageClass(StorageArchive)); err != nil {
+		t.Fatal(err)
+	}
+	if headers[0].Get("x-oss-object-acl") != "public-read" || headers[0].Get("x-oss-storage-class") != "IA" ||
+		headers[1].Get("x-oss-object-acl") != "default" || headers[1].Get("x-oss-storage-class") != "Archive" {
+		t.Error("wrong headers", headers)
+	}
+}
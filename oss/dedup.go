@@ -0,0 +1,57 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"os"
+	"sync"
+)
+
+// pendingUpload is an upload of a file whose md5 and content type are the same as
+// other files, done is closed after the upload is finished.
+type pendingUpload struct {
+	key  string
+	done chan struct{}
+	ok   bool
+}
+
+var (
+	dedup        bool
+	dedupMu      sync.Mutex
+	dedupUploads = map[string]*pendingUpload{}
+)
+
+// claimUpload returns the first upload of files with the same md5 and
+// content type, or, if there is none, a new upload for key which should be
+// finished by calling finish.
+func claimUpload(md5sum, contentType, key string) (first *pendingUpload, own *pendingUpload) {
+	dedupMu.Lock()
+	defer dedupMu.Unlock()
+	id := md5sum + " " + contentType
+	if first = dedupUploads[id]; first != nil {
+		return first, nil
+	}
+	own = &pendingUpload{key: key, done: make(chan struct{})}
+	dedupUploads[id] = own
+	return nil, own
+}
+
+func (u *pendingUpload) finish(ok bool) {
+	u.ok = ok
+	close(u.done)
+}
+
+// fileMD5 returns lower case hex md5 of local file.
+func fileMD5(localPath string) (string, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	md5sum := md5.New()
+	if _, err := io.Copy(md5sum, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(md5sum.Sum(nil)), nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/caiguanhao/ossslim"
+)
+
+// recursiveDelete deletes all files in directory prefix, or files matching
+// prefix if it is a glob pattern, see deleteFiles. The result of each file
+// and a summary are printed as JSON if -json is set.
+func recursiveDelete(prefix string, yes bool) {
+	deleted, failed := deleteFiles(prefix, yes)
+	if jsonOutput {
+		printSummary(deleteSummary{
+			Deleted: int64(deleted),
+			Failed:  int64(failed),
+		})
+	}
+	if dryrun {
+		log.Printf("%d files would be deleted\n", deleted)
+		return
+	}
+	if deleted == 0 && failed == 0 {
+		log.Println("no files to delete")
+		return
+	}
+	log.Printf("%d files deleted, %d errors\n", deleted, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// deleteFiles deletes files and returns the number of deleted and failed
+// files. The number of files is printed and confirmed first unless yes is
+// true, for a directory only the first two pages are listed before the
+// confirmation. Files in a directory are deleted page by page as they are
+// listed, so deletion starts without waiting for the whole directory to be
+// listed. In dry-run mode, files are only printed.
+func deleteFiles(prefix string, yes bool) (deleted, failed int) {
+	// sizes of listed files, unknown for glob patterns
+	sizes := map[string]int64{}
+	printResult := func(key, status, errMessage string) {
+		if jsonOutput {
+			printJSON(fileResult{
+				Path:   key,
+				URL:    client.URL(key),
+				Size:   sizes[key],
+				Status: status,
+				Error:  errMessage,
+			})
+		}
+	}
+	deleteKeys := func(keys []string) {
+		defer func() {
+			for _, key := range keys {
+				delete(sizes, key)
+			}
+		}()
+		if dryrun {
+			for _, key := range keys {
+				if jsonOutput {
+					printResult(key, "dryrun", "")
+				} else {
+					fmt.Println(client.URL(key))
+				}
+			}
+			deleted += len(keys)
+			return
+		}
+		// DeleteVerbose deletes in batches of 1000 keys
+		result, err := client.DeleteVerbose(keys...)
+		reported := map[string]bool{}
+		deleted += len(result.Deleted)
+		for _, key := range result.Deleted {
+			reported[key] = true
+			printResult(key, "deleted", "")
+		}
+		for _, e := range result.Errors {
+			reported[e.Key] = true
+			log.Println("error:", e.Key, e.Code, e.Message)
+			message := e.Message
+			if e.Code != "" {
+				message = e.Code + ": " + message
+			}
+			printResult(e.Key, "failed", message)
+		}
+		failed += len(result.Errors)
+		if err != nil {
+			log.Println(err)
+			// keys not in the result failed with err
+			for _, key := range keys {
+				if !reported[key] {
+					failed++
+					printResult(key, "failed", err.Error())
+				}
+			}
+		}
+		log.Printf("%d files deleted, %d errors so far\n", deleted, failed)
+	}
+	if strings.ContainsAny(prefix, `*?[\`) {
+		keys, err := client.Glob(prefix)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if len(keys) == 0 {
+			return
+		}
+		if !dryrun && !yes && !confirm(fmt.Sprintf("delete %d files matching %s?", len(keys), prefix)) {
+			log.Fatalln("aborted")
+		}
+		deleteKeys(keys)
+	} else {
+		// the first page is kept until the second page is listed, so the
+		// confirmation shows whether there are more files
+		confirmed := dryrun || yes
+		var pending []string
+		err := client.ListPages(prefix, true, func(page ossslim.ListResult) error {
+			keys := make([]string, 0, len(page.Files))
+			for _, file := range page.Files {
+				keys = append(keys, file.Name)
+				sizes[file.Name] = file.Size
+			}
+			if !confirmed {
+				if pending == nil {
+					pending = keys
+					return nil
+				}
+				if !confirm(fmt.Sprintf("delete %d+ files in %s?", len(pending)+len(keys), client.URL(prefix))) {
+					log.Fatalln("aborted")
+				}
+				confirmed = true
+				if len(pending) > 0 {
+					deleteKeys(pending)
+				}
+				pending = nil
+			}
+			if len(keys) > 0 {
+				deleteKeys(keys)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if len(pending) > 0 {
+			// all files are in the first page
+			if !confirm(fmt.Sprintf("delete %d files in %s?", len(pending), client.URL(prefix))) {
+				log.Fatalln("aborted")
+			}
+			deleteKeys(pending)
+		}
+	}
+	return
+}
+
+// confirm asks the user to type "y" or "yes" to continue.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
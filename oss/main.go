@@ -1,17 +1,16 @@
 package main
 
 import (
-	"bytes"
-	"crypto/md5"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/caiguanhao/ossslim"
 )
@@ -20,18 +19,55 @@ var (
 	client ossslim.Client
 	dryrun bool
 	nomd5  bool
+
+	skipUnchanged bool
+	remoteFiles   map[string]ossslim.File
+
+	remotePrefix string
+
+	followSymlinks bool
+
+	// options of uploads and copies, like ACL and storage class
+	uploadOptions []ossslim.Option
 )
 
 func main() {
 	var createConfig bool
 	var configFile string
 	var extsIgnore list
+	var concurrency int
+	var deletePrefix string
+	var yes bool
+	var maxInflight int64
+	var acl, storageClass string
 
 	flag.BoolVar(&createConfig, "C", false, "create config file and exit")
 	flag.StringVar(&configFile, "c", "oss.config", "config file location")
 	flag.BoolVar(&dryrun, "n", false, "show only URLs, don't upload")
 	flag.BoolVar(&nomd5, "nomd5", false, "do not compute md5")
 	flag.Var(&extsIgnore, "noext", "file extensions to ignore (for example -noext html)")
+	flag.IntVar(&concurrency, "j", runtime.NumCPU(), "number of concurrent uploads")
+	flag.BoolVar(&jsonOutput, "json", false, "print results as JSON, one object per line")
+	flag.BoolVar(&skipUnchanged, "skip-unchanged", false, "skip files with same size and md5 as remote files")
+	flag.StringVar(&remotePrefix, "prefix", "", "remote directory to upload files to (for example -prefix releases/v1.2)")
+	flag.BoolVar(&dedup, "dedup", false, "upload files with same content only once and copy them on the server side")
+	flag.StringVar(&acl, "acl", "", "ACL of uploaded files: default, private, public-read or public-read-write")
+	flag.StringVar(&storageClass, "storage-class", "", "storage class of uploaded files: Standard, IA, Archive or ColdArchive")
+	flag.BoolVar(&followSymlinks, "follow-symlinks", false, "upload files and directories that symlinks point to")
+	flag.StringVar(&deletePrefix, "recursive-delete", "", "delete all files in remote directory or matching glob pattern and exit")
+	flag.Int64Var(&maxInflight, "max-inflight", 0, "max total size in MiB of files read at the same time, 0 for no limit")
+	flag.BoolVar(&yes, "yes", false, "don't ask for confirmation before deleting files")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), `Usage: %s [options] <dir or file>...
+
+Files in a directory are uploaded with keys relative to the directory, for
+example, dist/js/app.js is uploaded to js/app.js if dist is given. A file is
+uploaded with its base name, for example, dist/index.html to index.html.
+
+Options:
+`, os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	if createConfig {
@@ -52,12 +88,6 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	args := flag.Args()
-	if len(args) != 1 {
-		log.Fatalln("must provide only one directory")
-	}
-	root := args[0]
-
 	client = ossslim.Client{
 		AccessKeyId:     currentConfig.OSSAccessKeyId,
 		AccessKeySecret: currentConfig.OSSAccessKeySecret,
@@ -65,80 +95,261 @@ func main() {
 		Bucket:          currentConfig.OSSBucket,
 	}
 
-	jobs := make(chan string)
-	go func() {
-		defer close(jobs)
-		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.Mode().IsRegular() {
-				return nil
-			}
-			ext := strings.TrimPrefix(filepath.Ext(path), ".")
-			if extsIgnore.Has(ext) {
-				return nil
-			}
-			name, err := filepath.Rel(root, path)
-			if err != nil {
-				return err
-			}
-			jobs <- name
-			return nil
-		})
+	if acl != "" {
+		uploadOptions = append(uploadOptions, ossslim.WithACL(acl))
+	}
+	if storageClass != "" {
+		uploadOptions = append(uploadOptions, ossslim.WithStorageClass(storageClass))
+	}
+
+	if deletePrefix != "" {
+		recursiveDelete(deletePrefix, yes)
+		return
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		log.Fatalln("must provide at least one directory or file")
+	}
+
+	if !dryrun {
+		checkBucket()
+	}
+
+	if skipUnchanged {
+		result, err := client.List(remotePrefix, true)
 		if err != nil {
 			log.Fatalln(err)
 		}
+		remoteFiles = map[string]ossslim.File{}
+		for _, file := range result.Files {
+			remoteFiles[file.Name] = file
+		}
+	}
+
+	var errs errorList
+	jobs := make(chan job)
+	go func() {
+		defer close(jobs)
+		w := walker{
+			extsIgnore: extsIgnore,
+			jobs:       jobs,
+			errs:       &errs,
+			visited:    map[string]bool{},
+		}
+		for _, arg := range args {
+			w.walk(arg)
+		}
 	}()
 
-	concurrency := runtime.NumCPU()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if maxInflight > 0 {
+		inflight = newByteSemaphore(maxInflight << 20)
+	}
+	var uploaded, copied, skipped, totalBytes int64
 	var wg sync.WaitGroup
 	wg.Add(concurrency)
 	for i := 0; i < concurrency; i++ {
 		go func() {
 			defer wg.Done()
-			for path := range jobs {
-				upload(root, path)
+			for j := range jobs {
+				result, err := upload(j.root, j.path)
+				if err != nil {
+					log.Println(err)
+					errs.Add(err)
+					result.Status = "failed"
+					result.Error = err.Error()
+				} else if result.Status == "skipped" {
+					atomic.AddInt64(&skipped, 1)
+				} else if result.Status == "copied" {
+					atomic.AddInt64(&copied, 1)
+				} else if !dryrun {
+					atomic.AddInt64(&uploaded, 1)
+					atomic.AddInt64(&totalBytes, result.Size)
+				}
+				if jsonOutput {
+					printJSON(result)
+				}
 			}
 		}()
 	}
 	wg.Wait()
-}
 
-func upload(root, path string) {
-	contentType := contentTypeForExtension(filepath.Ext(path))
-	if dryrun {
-		fmt.Printf("%s (%s)\n", client.URL(path), contentType)
-		return
+	if jsonOutput {
+		printSummary(summary{
+			Uploaded: uploaded,
+			Copied:   copied,
+			Skipped:  skipped,
+			Failed:   int64(len(errs.errs)),
+			Bytes:    totalBytes,
+		})
 	}
-	var buffer bytes.Buffer
-	file, err := os.Open(filepath.Join(root, path))
-	if err != nil {
-		log.Fatalln(err)
+	if dryrun && len(errs.errs) == 0 {
 		return
 	}
-	defer file.Close()
-	if nomd5 == false {
-		md5sum := md5.New()
-		n, err := io.Copy(io.MultiWriter(&buffer, md5sum), file)
+	log.Printf("%d files uploaded, %d copied, %d skipped, %d errors\n", uploaded, copied, skipped, len(errs.errs))
+	if len(errs.errs) > 0 {
+		for _, err := range errs.errs {
+			log.Println("error:", err)
+		}
+		code := len(errs.errs)
+		if code > 125 {
+			code = 125
+		}
+		os.Exit(code)
+	}
+}
+
+// job is a file to upload, path is relative to root and is also the remote
+// key of the file (after -prefix).
+type job struct {
+	root string
+	path string
+}
+
+// checkBucket exits if the bucket does not exist or is in another region.
+// Other errors are ignored, as the access key may have no permission to get
+// bucket location but can still upload files.
+func checkBucket() {
+	exists, err := client.BucketExists()
+	var wrongRegion *ossslim.WrongRegionError
+	if errors.As(err, &wrongRegion) {
+		log.Fatalln("bucket", client.Bucket, "must be accessed using endpoint", wrongRegion.Endpoint)
+	}
+	if err == nil && !exists {
+		log.Fatalln("bucket", client.Bucket, "does not exist")
+	}
+}
+
+// remoteKey returns the remote key of path, which is relative to its root,
+// with -prefix prepended.
+func remoteKey(path string) string {
+	key := filepath.ToSlash(path)
+	if prefix := strings.Trim(remotePrefix, "/"); prefix != "" {
+		key = prefix + "/" + key
+	}
+	return key
+}
+
+func upload(root, path string) (result fileResult, err error) {
+	contentType := ossslim.ContentTypeForExtension(filepath.Ext(path))
+	key := remoteKey(path)
+	result = fileResult{
+		Path:        path,
+		URL:         client.URL(key),
+		ContentType: contentType,
+	}
+	localPath := filepath.Join(root, path)
+	if inflight != nil {
+		info, err := os.Stat(localPath)
 		if err != nil {
-			log.Fatalln(err)
-			return
+			return result, err
+		}
+		defer inflight.release(inflight.acquire(info.Size()))
+	}
+	if skipUnchanged {
+		unchanged, err := isUnchanged(key, localPath)
+		if err != nil {
+			return result, err
+		}
+		if unchanged {
+			result.Status = "skipped"
+			log.Println("skipped unchanged", result.URL)
+			return result, nil
 		}
-		req, err := client.Upload(path, &buffer, md5sum.Sum(nil), contentType)
+	}
+	if dedup {
+		var md5sum string
+		md5sum, err = fileMD5(localPath)
 		if err != nil {
-			log.Fatalln("failed to upload to", req.URL(), err)
 			return
 		}
-		log.Printf("uploaded to %s (%d bytes)\n", req.URL(), n)
+		first, own := claimUpload(md5sum, contentType, key)
+		if own != nil {
+			defer func() { own.finish(err == nil) }()
+		} else {
+			// wait for the first file, upload this file if it failed
+			<-first.done
+			if first.ok {
+				return copyDuplicate(result, first.key, key, md5sum)
+			}
+		}
+	}
+	if dryrun {
+		result.Status = "dryrun"
+		if !jsonOutput {
+			fmt.Printf("%s (%s)\n", result.URL, contentType)
+		}
+		return
+	}
+	var req *ossslim.Request
+	if nomd5 == false {
+		// md5 is computed in a first pass, file is streamed in a second
+		// pass, so memory usage doesn't grow with file size
+		req, err = client.UploadFile(key, localPath, contentType, uploadOptions...)
 	} else {
-		req, err := client.Upload(path, file, nil, contentType)
+		var file *os.File
+		file, err = os.Open(localPath)
 		if err != nil {
-			log.Fatalln("failed to upload to", req.URL(), err)
 			return
 		}
-		log.Printf("uploaded to %s\n", req.URL())
+		defer file.Close()
+		req, err = client.Upload(key, file, nil, contentType, uploadOptions...)
+	}
+	if err != nil {
+		if req != nil {
+			err = fmt.Errorf("failed to upload to %s: %w", req.URL(), err)
+		}
+		return
+	}
+	result.Size = req.BytesSent
+	result.MD5 = md5FromETag(req.ETag)
+	result.Status = "uploaded"
+	log.Printf("uploaded to %s (%d bytes)\n", req.URL(), req.BytesSent)
+	return
+}
+
+// copyDuplicate copies remote file src, which has the same content as the
+// local file, to dst on the server side instead of uploading the file again.
+func copyDuplicate(result fileResult, src, dst, md5sum string) (fileResult, error) {
+	if dryrun {
+		result.Status = "dryrun"
+		if !jsonOutput {
+			fmt.Printf("%s (%s, duplicate of %s)\n", result.URL, result.ContentType, src)
+		}
+		return result, nil
+	}
+	if _, err := client.Copy(src, dst, uploadOptions...); err != nil {
+		return result, fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
 	}
+	result.MD5 = md5sum
+	result.Status = "copied"
+	log.Printf("copied %s to %s\n", client.URL(src), result.URL)
+	return result, nil
+}
+
+// isUnchanged returns true if remote file exists and has the same size and
+// md5 as the local file. Files uploaded by multipart upload are considered
+// changed as their ETags are not md5.
+func isUnchanged(key, localPath string) (bool, error) {
+	remote, ok := remoteFiles[key]
+	if !ok {
+		return false, nil
+	}
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != remote.Size {
+		return false, nil
+	}
+	md5sum, err := fileMD5(localPath)
+	if err != nil {
+		return false, err
+	}
+	return md5sum == md5FromETag(remote.ETag), nil
 }
 
 type list []string
@@ -160,3 +371,15 @@ func (s list) Has(str string) bool {
 	}
 	return false
 }
+
+// errorList collects errors from multiple goroutines.
+type errorList struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (l *errorList) Add(err error) {
+	l.mu.Lock()
+	l.errs = append(l.errs, err)
+	l.mu.Unlock()
+}
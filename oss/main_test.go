@@ -0,0 +1,110 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caiguanhao/ossslim"
+)
+
+const emptyMD5 = "d41d8cd98f00b204e9800998ecf8427e"
+
+func TestWalkEmptyFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "a", ".keep"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	jobs := make(chan job, 10)
+	var errs errorList
+	w := walker{jobs: jobs, errs: &errs, visited: map[string]bool{}}
+	w.walk(dir)
+	close(jobs)
+	var got []job
+	for j := range jobs {
+		got = append(got, j)
+	}
+	if len(errs.errs) > 0 {
+		t.Fatal(errs.errs)
+	}
+	if len(got) != 1 || got[0].root != dir || got[0].path != filepath.Join("a", ".keep") {
+		t.Error("empty file should be walked", got)
+	}
+}
+
+func TestUploadEmptyFile(t *testing.T) {
+	var puts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/a/.keep" {
+			t.Error("wrong request", r.Method, r.URL.Path)
+		}
+		puts++
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.ContentLength != 0 || len(body) != 0 {
+			t.Error("wrong body", r.ContentLength, len(body))
+		}
+		w.Header().Set("ETag", `"`+emptyMD5+`"`)
+	}))
+	defer server.Close()
+	defer func() {
+		client = ossslim.Client{}
+		inflight = nil
+		skipUnchanged = false
+		remoteFiles = nil
+	}()
+	client = ossslim.Client{
+		AccessKeyId:     "foo",
+		AccessKeySecret: "bar",
+		Prefix:          server.URL,
+		Bucket:          "test",
+	}
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "a", ".keep"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// an empty file acquires nothing, so it doesn't wait for other files
+	inflight = newByteSemaphore(1)
+	inflight.acquire(1)
+	done := make(chan struct{})
+	var result fileResult
+	var err error
+	go func() {
+		defer close(done)
+		result, err = upload(dir, filepath.Join("a", ".keep"))
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("upload of empty file is blocked by -max-inflight")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "uploaded" || result.Size != 0 || result.MD5 != emptyMD5 || puts != 1 {
+		t.Error("wrong result", result, puts)
+	}
+
+	// an empty remote file with the same md5 is unchanged
+	skipUnchanged = true
+	remoteFiles = map[string]ossslim.File{
+		"a/.keep": {Name: "a/.keep", ETag: `"` + emptyMD5 + `"`},
+	}
+	result, err = upload(dir, filepath.Join("a", ".keep"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "skipped" || puts != 1 {
+		t.Error("empty file should be skipped", result, puts)
+	}
+}
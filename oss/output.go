@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+)
+
+type (
+	// fileResult is printed as one line of JSON for every file if -json is
+	// set.
+	fileResult struct {
+		Path        string `json:"path"`
+		URL         string `json:"url"`
+		Size        int64  `json:"size"`
+		MD5         string `json:"md5,omitempty"`
+		ContentType string `json:"content_type,omitempty"`
+		Status      string `json:"status"`
+		Error       string `json:"error,omitempty"`
+	}
+
+	summary struct {
+		Uploaded int64 `json:"uploaded"`
+		Copied   int64 `json:"copied"`
+		Skipped  int64 `json:"skipped"`
+		Failed   int64 `json:"failed"`
+		Bytes    int64 `json:"bytes"`
+	}
+
+	// deleteSummary is printed at the end of -recursive-delete if -json is
+	// set. In dry-run mode, Deleted is the number of files to be deleted.
+	deleteSummary struct {
+		Deleted int64 `json:"deleted"`
+		Failed  int64 `json:"failed"`
+	}
+)
+
+var (
+	jsonOutput bool
+	outputMu   sync.Mutex
+)
+
+func printJSON(v interface{}) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	json.NewEncoder(os.Stdout).Encode(v)
+}
+
+func printSummary(s interface{}) {
+	printJSON(struct {
+		Summary interface{} `json:"summary"`
+	}{s})
+}
+
+// md5FromETag returns lower case hex MD5 from ETag of a file uploaded in a
+// single request.
+func md5FromETag(etag string) string {
+	return strings.ToLower(strings.Trim(etag, `"`))
+}
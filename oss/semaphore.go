@@ -0,0 +1,51 @@
+package main
+
+import (
+	"sync"
+)
+
+// byteSemaphore limits total size of files being read at the same time. A
+// nil byteSemaphore has no limit.
+type byteSemaphore struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	limit int64
+	used  int64
+}
+
+var inflight *byteSemaphore
+
+func newByteSemaphore(limit int64) *byteSemaphore {
+	s := &byteSemaphore{limit: limit}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// acquire waits until n bytes are available and returns the number of bytes
+// acquired, which should be passed to release. Files larger than the limit
+// acquire the whole limit, so they are read one at a time.
+func (s *byteSemaphore) acquire(n int64) int64 {
+	if s == nil {
+		return 0
+	}
+	if n > s.limit {
+		n = s.limit
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.used+n > s.limit {
+		s.cond.Wait()
+	}
+	s.used += n
+	return n
+}
+
+func (s *byteSemaphore) release(n int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.used -= n
+	s.mu.Unlock()
+	s.cond.Broadcast()
+}
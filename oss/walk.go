@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// walker finds files to upload.
+type walker struct {
+	extsIgnore list
+	jobs       chan<- job
+	errs       *errorList
+
+	// real paths of directories walked, to avoid walking a directory
+	// twice through symlinks, which may form a cycle
+	visited map[string]bool
+}
+
+// walk sends files in directory or file arg to jobs. Files in a directory
+// are uploaded with keys relative to the directory, for example, file
+// dist/js/app.js is uploaded to js/app.js if arg is dist. If arg is a file,
+// it is uploaded to its base name, for example, file dist/index.html is
+// uploaded to index.html.
+func (w *walker) walk(arg string) {
+	info, err := os.Stat(arg)
+	if err != nil {
+		w.errs.Add(err)
+		return
+	}
+	if !info.IsDir() {
+		w.add(filepath.Dir(arg), filepath.Base(arg))
+		return
+	}
+	if real, err := filepath.EvalSymlinks(arg); err == nil {
+		w.visited[real] = true
+	}
+	w.walkDir(arg, arg)
+}
+
+// walkDir walks directory dir which is root or inside root. If
+// -follow-symlinks is set, symlinks to files are uploaded like regular files
+// and symlinks to directories are walked unless the directories have been
+// walked through another symlink or contain the symlinks.
+func (w *walker) walkDir(root, dir string) {
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			w.errs.Add(err)
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 && followSymlinks {
+			target, err := os.Stat(path)
+			if err != nil {
+				w.errs.Add(err)
+				return nil
+			}
+			if !target.IsDir() {
+				info = target
+			} else {
+				real, err := filepath.EvalSymlinks(path)
+				if err != nil {
+					w.errs.Add(err)
+					return nil
+				}
+				if !w.visited[real] && !isAncestor(real, filepath.Dir(path)) {
+					w.visited[real] = true
+					w.walkDir(root, path+string(filepath.Separator))
+				}
+				return nil
+			}
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			w.errs.Add(err)
+			return nil
+		}
+		w.add(root, name)
+		return nil
+	})
+}
+
+func (w *walker) add(root, path string) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if w.extsIgnore.Has(ext) {
+		return
+	}
+	w.jobs <- job{root, path}
+}
+
+// isAncestor returns true if real path dir is the same as or contains
+// directory path.
+func isAncestor(dir, path string) bool {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, real)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
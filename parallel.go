@@ -0,0 +1,84 @@
+package ossslim
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// offsetWriter writes to w sequentially starting at offset.
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (o *offsetWriter) Write(p []byte) (n int, err error) {
+	n, err = o.w.WriteAt(p, o.offset)
+	o.offset += int64(n)
+	return
+}
+
+// DownloadParallel wraps DownloadParallelWithContext using
+// context.Background.
+func (c *Client) DownloadParallel(remote string, w io.WriterAt, parts int, options ...Option) (int64, error) {
+	return c.DownloadParallelWithContext(context.Background(), remote, w, parts, options...)
+}
+
+// DownloadParallelWithContext downloads remote file to w (like *os.File)
+// using parts concurrent ranged downloads, returns the size of the file. The
+// size is obtained by Stat first, and each part is downloaded only if the
+// ETag of the file is unchanged. If any part fails, other parts are canceled
+// and the first error is returned.
+func (c *Client) DownloadParallelWithContext(ctx context.Context, remote string, w io.WriterAt, parts int, options ...Option) (int64, error) {
+	info, _, err := c.StatWithContext(ctx, remote, options...)
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size
+	if size == 0 {
+		return 0, nil
+	}
+	if parts < 1 {
+		parts = 1
+	}
+	partSize := (size + int64(parts) - 1) / int64(parts)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for start := int64(0); start < size; start += partSize {
+		end := start + partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		opts := make([]Option, 0, len(options)+2)
+		opts = append(opts, options...)
+		opts = append(opts, WithRange(start, end), withIfMatch(info.ETag))
+		wg.Add(1)
+		go func(start, end int64) {
+			defer wg.Done()
+			req, err := c.DownloadWithContext(ctx, remote, &offsetWriter{w, start}, opts...)
+			if err == nil && req.Response.StatusCode != 206 && (start > 0 || end < size-1) {
+				err = errors.New("range is not supported")
+			}
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	return size, firstErr
+}
+
+func withIfMatch(etag string) Option {
+	return func(req *Request) {
+		if etag != "" {
+			req.setHeader("If-Match", etag)
+		}
+	}
+}
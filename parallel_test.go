@@ -0,0 +1,46 @@
+package ossslim
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadParallel(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+	var ranged int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			if r.Header.Get("Range") == "" || r.Header.Get("If-Match") != `"foo"` {
+				t.Error("wrong headers", r.Header)
+			}
+			atomic.AddInt32(&ranged, 1)
+		}
+		w.Header().Set("ETag", `"foo"`)
+		http.ServeContent(w, r, "foo", time.Time{}, bytes.NewReader(content))
+	})
+	file, err := os.Create(filepath.Join(t.TempDir(), "foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	size, err := client.DownloadParallel("foo", file, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(len(content)) || ranged != 3 {
+		t.Error("wrong size or number of requests", size, ranged)
+	}
+	downloaded := make([]byte, size)
+	if _, err := file.ReadAt(downloaded, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(downloaded, content) {
+		t.Error("wrong content")
+	}
+}
@@ -0,0 +1,50 @@
+package ossslim
+
+import "io"
+
+type (
+	// ProgressReader wraps Reader and calls OnProgress with the total number
+	// of bytes read so far after every read. It can wrap the body of Upload
+	// to show upload progress. Note that a ProgressReader is not an
+	// io.Seeker, so the body can not be rewound for retries unless SpoolBody
+	// is used.
+	ProgressReader struct {
+		Reader     io.Reader
+		OnProgress func(total int64)
+
+		total int64
+	}
+
+	// ProgressWriter wraps Writer and calls OnProgress with the total number
+	// of bytes written so far after every write. It can wrap the destination
+	// of Download to show download progress, for example, with the
+	// ResponseContentLength of the request as the total size.
+	ProgressWriter struct {
+		Writer     io.Writer
+		OnProgress func(total int64)
+
+		total int64
+	}
+)
+
+func (r *ProgressReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if n > 0 {
+		r.total += int64(n)
+		if r.OnProgress != nil {
+			r.OnProgress(r.total)
+		}
+	}
+	return
+}
+
+func (w *ProgressWriter) Write(p []byte) (n int, err error) {
+	n, err = w.Writer.Write(p)
+	if n > 0 {
+		w.total += int64(n)
+		if w.OnProgress != nil {
+			w.OnProgress(w.total)
+		}
+	}
+	return
+}
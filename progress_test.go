@@ -0,0 +1,49 @@
+package ossslim
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	content := strings.Repeat("a", 100000)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			ioutil.ReadAll(r.Body)
+			return
+		}
+		w.Write([]byte(content))
+	})
+	var uploaded, calls int64
+	reader := &ProgressReader{
+		Reader: strings.NewReader(content),
+		OnProgress: func(total int64) {
+			if total <= uploaded {
+				t.Error("total should increase", total)
+			}
+			uploaded = total
+			calls++
+		},
+	}
+	if _, err := client.Upload("foo", reader, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if uploaded != int64(len(content)) || calls < 2 {
+		t.Error("wrong progress", uploaded, calls)
+	}
+	var downloaded int64
+	var buf bytes.Buffer
+	writer := &ProgressWriter{
+		Writer:     &buf,
+		OnProgress: func(total int64) { downloaded = total },
+	}
+	if _, err := client.Download("foo", writer); err != nil {
+		t.Fatal(err)
+	}
+	if downloaded != int64(len(content)) || buf.String() != content {
+		t.Error("wrong progress", downloaded)
+	}
+}
@@ -0,0 +1,117 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ReaderAt reads a remote file at random offsets using ranged downloads,
+// without downloading the whole file. It is safe for concurrent use.
+type ReaderAt struct {
+	client *Client
+	ctx    context.Context
+	remote string
+
+	once sync.Once
+	size int64
+	err  error
+}
+
+// WithRange makes a download request only download bytes from start to end
+// (inclusive) of the file. If end is negative, bytes from start to the end
+// of the file are downloaded.
+func WithRange(start, end int64) Option {
+	return func(req *Request) {
+		if end < 0 {
+			req.setHeader("Range", fmt.Sprintf("bytes=%d-", start))
+		} else {
+			req.setHeader("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+		}
+	}
+}
+
+// parseContentRangeTotal returns the total size in a Content-Range header
+// like "bytes 0-99/1000", or -1 if it is unknown.
+func parseContentRangeTotal(contentRange string) int64 {
+	i := strings.LastIndexByte(contentRange, '/')
+	if i < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+// NewReaderAt wraps NewReaderAtWithContext using context.Background.
+func (c *Client) NewReaderAt(remote string, size int64) *ReaderAt {
+	return c.NewReaderAtWithContext(context.Background(), remote, size)
+}
+
+// NewReaderAtWithContext returns an io.ReaderAt of remote file whose size
+// is size. If size is negative, it is obtained by Stat when first needed.
+func (c *Client) NewReaderAtWithContext(ctx context.Context, remote string, size int64) *ReaderAt {
+	r := &ReaderAt{
+		client: c,
+		ctx:    ctx,
+		remote: remote,
+		size:   size,
+	}
+	if size >= 0 {
+		r.once.Do(func() {})
+	}
+	return r
+}
+
+// Size returns size of the remote file.
+func (r *ReaderAt) Size() (int64, error) {
+	r.once.Do(func() {
+		var info *FileInfo
+		info, _, r.err = r.client.StatWithContext(r.ctx, r.remote)
+		if r.err == nil {
+			r.size = info.Size
+		}
+	})
+	return r.size, r.err
+}
+
+// ReadAt implements io.ReaderAt.
+func (r *ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	size, err := r.Size()
+	if err != nil {
+		return 0, err
+	}
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset: %d", off)
+	}
+	if off >= size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n := int64(len(p))
+	if off+n > size {
+		n = size - off
+	}
+	buf := bytes.NewBuffer(p[:0])
+	req, err := r.client.DownloadWithContext(r.ctx, r.remote, buf, WithRange(off, off+n-1))
+	if err != nil {
+		return 0, err
+	}
+	if req.Response.StatusCode != 206 && (off > 0 || int64(buf.Len()) != n) {
+		return 0, errors.New("range is not supported")
+	}
+	read := copy(p, buf.Bytes())
+	if read < len(p) {
+		return read, io.EOF
+	}
+	return read, nil
+}
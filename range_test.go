@@ -0,0 +1,75 @@
+package ossslim
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestReaderAt(t *testing.T) {
+	var zipFile bytes.Buffer
+	w := zip.NewWriter(&zipFile)
+	f, _ := w.Create("hello.txt")
+	f.Write([]byte("hello world"))
+	w.Close()
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.ServeContent(w, r, "foo.zip", time.Time{}, bytes.NewReader(zipFile.Bytes()))
+	})
+	reader := client.NewReaderAt("foo.zip", -1)
+	size, err := reader.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(zipFile.Len()) {
+		t.Fatal("wrong size", size)
+	}
+	z, err := zip.NewReader(reader, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := z.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	content, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello world" {
+		t.Fatal("wrong content", string(content))
+	}
+	if requests < 3 {
+		t.Fatal("should use ranged requests")
+	}
+}
+
+func TestTotalSize(t *testing.T) {
+	content := []byte("hello world")
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "foo.txt", time.Time{}, bytes.NewReader(content))
+	})
+	var buf bytes.Buffer
+	req, err := client.Download("foo.txt", &buf, WithRange(0, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello" || req.TotalSize != int64(len(content)) {
+		t.Error("wrong ranged download", buf.String(), req.TotalSize)
+	}
+	req, err = client.Download("foo.txt", ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.TotalSize != int64(len(content)) {
+		t.Error("wrong total size", req.TotalSize)
+	}
+	if total := parseContentRangeTotal("bytes 0-4/*"); total != -1 {
+		t.Error("wrong total size", total)
+	}
+}
@@ -0,0 +1,128 @@
+package ossslim
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// rateLimiter is a token bucket shared by all requests of a client.
+	rateLimiter struct {
+		mu     sync.Mutex
+		rate   int64
+		tokens float64
+		last   time.Time
+	}
+
+	rateLimitedReader struct {
+		ctx     context.Context
+		reader  io.Reader
+		limiter *rateLimiter
+	}
+
+	rateLimitedWriter struct {
+		ctx     context.Context
+		writer  io.Writer
+		limiter *rateLimiter
+	}
+)
+
+// max bytes read or written at a time
+const rateLimitChunkSize = 32 * 1024
+
+// WithTrafficLimit makes OSS limit the speed of an upload or download request
+// to bitsPerSecond bits per second, which must be between 819200 (100 KB/s)
+// and 838860800 (100 MB/s). Unlike MaxBytesPerSecond of Client, the limit
+// applies to this request only and is enforced on the server side.
+func WithTrafficLimit(bitsPerSecond int64) Option {
+	return func(req *Request) {
+		req.setHeader("x-oss-traffic-limit", strconv.FormatInt(bitsPerSecond, 10))
+	}
+}
+
+func (c *Client) rateLimiter() *rateLimiter {
+	if c.MaxBytesPerSecond <= 0 {
+		return nil
+	}
+	c.limiterMu.Lock()
+	defer c.limiterMu.Unlock()
+	if c.limiter == nil || c.limiter.rate != c.MaxBytesPerSecond {
+		c.limiter = &rateLimiter{
+			rate:   c.MaxBytesPerSecond,
+			tokens: float64(c.MaxBytesPerSecond),
+			last:   time.Now(),
+		}
+	}
+	return c.limiter
+}
+
+// wait takes n tokens from the bucket and sleeps until they are available.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * float64(l.rate)
+	if l.tokens > float64(l.rate) {
+		l.tokens = float64(l.rate)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func chunkSize(size int, rate int64) int {
+	if size > rateLimitChunkSize {
+		size = rateLimitChunkSize
+	}
+	if int64(size) > rate {
+		size = int(rate)
+	}
+	return size
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n, err := r.reader.Read(p[:chunkSize(len(p), r.limiter.rate)])
+	if n > 0 {
+		if werr := r.limiter.wait(r.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+func (w *rateLimitedWriter) Write(p []byte) (written int, err error) {
+	for len(p) > 0 {
+		size := chunkSize(len(p), w.limiter.rate)
+		if err = w.limiter.wait(w.ctx, size); err != nil {
+			return
+		}
+		var n int
+		n, err = w.writer.Write(p[:size])
+		written += n
+		if err != nil {
+			return
+		}
+		p = p[size:]
+	}
+	return
+}
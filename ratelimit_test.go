@@ -0,0 +1,44 @@
+package ossslim
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 15000)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	})
+	client.MaxBytesPerSecond = 10000
+	start := time.Now()
+	var buf bytes.Buffer
+	if _, err := client.Download("foo", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), content) {
+		t.Fatal("wrong content")
+	}
+	// first 10000 bytes are allowed immediately
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 2*time.Second {
+		t.Fatal("wrong elapsed time", elapsed)
+	}
+}
+
+func TestTrafficLimit(t *testing.T) {
+	var limits []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		limits = append(limits, r.Header.Get("x-oss-traffic-limit"))
+	})
+	if _, err := client.Upload("foo", bytes.NewReader([]byte("foo")), nil, "", WithTrafficLimit(819200)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Download("foo", &bytes.Buffer{}, WithTrafficLimit(838860800)); err != nil {
+		t.Fatal(err)
+	}
+	if len(limits) != 2 || limits[0] != "819200" || limits[1] != "838860800" {
+		t.Error("wrong traffic limits", limits)
+	}
+}
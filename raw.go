@@ -0,0 +1,40 @@
+package ossslim
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Do wraps DoWithContext using context.Background.
+func (c *Client) Do(method, remote string, query url.Values, header http.Header, reqBody io.Reader, respBody io.Writer) (*Request, error) {
+	return c.DoWithContext(context.Background(), method, remote, query, header, reqBody, respBody)
+}
+
+// DoWithContext sends a signed request, which can be used to call OSS APIs
+// without a method in this package. Use "/" as remote for bucket APIs. Signed
+// subresources in query (like "acl" or "uploadId") and x-oss-* headers are
+// signed, Content-Type and Content-MD5 in header are also signed. reqBody can
+// be nil. If the response has a 2xx status code, its body is written to
+// respBody, which can be nil to discard it. Otherwise an *Error is returned.
+// Headers of the response are available in Response of the returned request.
+func (c *Client) DoWithContext(ctx context.Context, method, remote string, query url.Values, header http.Header, reqBody io.Reader, respBody io.Writer) (*Request, error) {
+	if remote == "" {
+		remote = "/"
+	}
+	req := &Request{
+		client:   c,
+		ctx:      ctx,
+		remote:   remote,
+		method:   method,
+		reqBody:  reqBody,
+		respBody: respBody,
+		queries:  url.Values{},
+	}
+	for key, values := range query {
+		req.queries[key] = append([]string(nil), values...)
+	}
+	WithHeaders(header)(req)
+	return req, req.do()
+}
@@ -0,0 +1,43 @@
+package ossslim
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		msg := strings.Join([]string{r.Method, r.Header.Get("Content-MD5"), r.Header.Get("Content-Type"),
+			r.Header.Get("Date"), "x-oss-object-acl:private\n/test/foo?acl"}, "\n")
+		mac := hmac.New(sha1.New, []byte("bar"))
+		mac.Write([]byte(msg))
+		if auth := "OSS foo:" + base64.StdEncoding.EncodeToString(mac.Sum(nil)); r.Header.Get("Authorization") != auth {
+			t.Error("wrong authorization", r.Header.Get("Authorization"))
+		}
+		if r.URL.RawQuery != "acl&foo=bar" || string(body) != "hello" || r.Header.Get("Content-MD5") != "bar" {
+			t.Error("wrong request", r.URL.RawQuery, string(body), r.Header)
+		}
+		w.Header().Set("x-oss-request-id", "1")
+		w.Write([]byte("world"))
+	})
+	var buf bytes.Buffer
+	req, err := client.Do("PUT", "foo", url.Values{"acl": {""}, "foo": {"bar"}}, http.Header{
+		"X-Oss-Object-Acl": {"private"},
+		"Content-Type":     {"text/plain"},
+		"Content-MD5":      {"bar"},
+	}, strings.NewReader("hello"), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "world" || req.Response.Header.Get("x-oss-request-id") != "1" {
+		t.Error("wrong response", buf.String(), req.Response.Header)
+	}
+}
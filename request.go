@@ -7,50 +7,238 @@ import (
 	"crypto/md5"
 	"crypto/sha1"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
-	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	FlavorOSS = "oss"
+	FlavorS3  = "s3"
+)
+
 type (
 	// An OSS client must have prefix, bucket, access key ID and access key secret.
 	// Prefix should be a string like this: https://<your-bucket>.<region>.aliyuncs.com.
+	// Prefix can also be your custom domain (CNAME) bound to the bucket.
+	//
+	// A Client can be used by multiple goroutines concurrently, but its
+	// fields must be set before it is used and not changed afterwards. To
+	// rotate credentials of a client in use, set CredentialsProvider.
+	//
+	// Requests are sent with Accept-Encoding: identity, so files stored with
+	// Content-Encoding: gzip are downloaded as stored (compressed). Earlier
+	// versions let http.Transport decompress them; use WithDecompress to get
+	// the original bytes.
 	Client struct {
 		AccessKeyId     string
 		AccessKeySecret string
 		Prefix          string
 		Bucket          string
+
+		// SecurityToken is the STS security token of temporary
+		// AccessKeyId and AccessKeySecret.
+		SecurityToken string
+
+		// If CredentialsProvider is not nil, it is called before every
+		// request (including retries) to get the credentials, instead of
+		// using AccessKeyId, AccessKeySecret and SecurityToken, so that
+		// credentials can be rotated without recreating the client. It
+		// should cache the credentials and be safe for concurrent use.
+		CredentialsProvider func(ctx context.Context) (Credentials, error)
+
+		// Prefix is used to generate URLs of files and, if Endpoint is empty,
+		// requests are also sent to Prefix. If Endpoint is not empty,
+		// requests are sent to Endpoint instead, while Prefix is still used
+		// in URL(). Endpoint should not contain the bucket name, like
+		// https://oss-cn-hangzhou.aliyuncs.com, the bucket name is added to
+		// the host name (virtual-hosted style) or, if PathStyle is true, to
+		// the path (https://<endpoint>/<bucket>/<key>) which is used by some
+		// S3-compatible services. The signature is not affected, the signed
+		// resource is always /<bucket>/<key>. For example, set Endpoint to
+		// https://oss-cn-hangzhou-internal.aliyuncs.com to upload through
+		// the free internal network when running in Alibaba Cloud, or to
+		// https://oss-accelerate.aliyuncs.com to use transfer acceleration,
+		// while URL() still returns public URLs of Prefix. In tests, set
+		// Endpoint to the URL of an httptest.Server with PathStyle to
+		// send signed requests to the test server.
+		Endpoint  string
+		PathStyle bool
+
+		// If VerifyCRC64 is true, CRC64 (ECMA) of the body will be computed
+		// during upload and download and compared with the
+		// x-oss-hash-crc64ecma header returned by OSS. Ranged and async
+		// downloads are not verified. It is disabled by default as it costs
+		// extra CPU time for every upload and download.
+		VerifyCRC64 bool
+
+		// If MaxBytesPerSecond is greater than 0, request and response bodies
+		// will be throttled to this rate. The limit is shared by all
+		// concurrent requests of the client.
+		MaxBytesPerSecond int64
+
+		// TimeOffset is added to the local time used in signatures. If the
+		// local clock is wrong, OSS responds with RequestTimeTooSkewed error
+		// whose ServerTime can be used to set TimeOffset, like this:
+		//  client.TimeOffset = time.Until(err.(*ossslim.Error).ServerTime)
+		TimeOffset time.Duration
+
+		// If DefaultTimeout is greater than 0, requests whose context has no
+		// deadline (for example, methods without WithContext) will be
+		// canceled after this duration, including the time to read the
+		// response body.
+		DefaultTimeout time.Duration
+
+		// Flavor is FlavorOSS (default) or FlavorS3. If it is FlavorS3,
+		// requests are signed with AWS signature version 2 so the client
+		// can be used with S3-compatible services like MinIO or Ceph, and
+		// x-oss-* headers are sent as x-amz-* headers. Features only
+		// available in OSS (like image info and callback) can not be used.
+		Flavor string
+
+		// SignatureVersion is 1 (default) or 4. Signature version 4 uses
+		// HMAC-SHA256 and is scoped to Region, which is required by some
+		// newer regions and features of OSS. It is ignored if Flavor is
+		// FlavorS3.
+		SignatureVersion int
+
+		// Region is the region of the bucket used in signature version 4,
+		// like "cn-hangzhou". If it is empty, it is taken from the host name
+		// of Endpoint or Prefix.
+		Region string
+
+		// If DryRun is true, Upload, Delete and DeleteRecursive (and other
+		// methods based on them) log what would be uploaded or deleted to
+		// Logger and return without sending requests to OSS.
+		DryRun bool
+
+		// Logger is used to log messages like dry run results. Nothing is
+		// logged if it is nil. *log.Logger can be used.
+		Logger Logger
+
+		// MaxRetries is the number of times a request is retried if it
+		// fails and ShouldRetry returns true. Requests are retried only if
+		// their bodies can be rewound, i.e. the body is an io.Seeker (like
+		// *os.File and *bytes.Reader) or is spooled with the SpoolBody
+		// option. Retries are delayed by 100 ms, 200 ms, 400 ms and so on,
+		// up to 10 seconds, or by the duration in the Retry-After header of
+		// the response (usually 503 Service Unavailable) if it is present,
+		// also up to 10 seconds.
+		MaxRetries int
+
+		// ShouldRetry decides whether a failed request should be retried.
+		// resp is nil if no response was received, its body has already
+		// been read and closed. attempt is 1 for the first failure. If it is
+		// nil, DefaultShouldRetry is used.
+		ShouldRetry func(resp *http.Response, err error, attempt int) bool
+
+		// If OnRequestStart and OnRequestDone are not nil, they are called
+		// before and after every HTTP request (including retries), which
+		// can be used to collect metrics or trace requests. bytes is the
+		// number of bytes of the request and response bodies transferred,
+		// status is 0 if no response is received. For DownloadAsync,
+		// OnRequestDone is called after the body is downloaded.
+		OnRequestStart func(method, url string)
+		OnRequestDone  func(method, url string, status int, bytes int64, duration time.Duration, err error)
+
+		// DeleteConcurrency is the number of batches of 1000 keys deleted
+		// concurrently by Delete and DeleteVerbose, default is 1. Once a
+		// batch fails, no more batches are deleted.
+		DeleteConcurrency int
+
+		// HTTPClient is used to send requests, http.DefaultClient is used
+		// if it is nil. Set it to share a transport (and its connection
+		// pool) between clients or to customize timeouts and proxies.
+		// Redirects are not followed unless its CheckRedirect is set, in
+		// which case redirects are handled by CheckRedirect.
+		HTTPClient *http.Client
+
+		limiterMu sync.Mutex
+		limiter   *rateLimiter
 	}
 
 	Request struct {
+		// Response is the HTTP response of the request. It is also available
+		// if OSS responds with an error status code, in which case the body
+		// of the response has already been read and closed.
 		Response              *http.Response
 		ResponseContentLength *int64
 
+		// ETag returned by OSS, with double quotes, same as ETag of File.
+		ETag string
+
+		// Number of bytes of the request body sent.
+		BytesSent int64
+
+		// TotalSize is the size of the whole remote file of a download
+		// request. For ranged downloads (see WithRange), it is parsed from
+		// the Content-Range header. It is -1 if unknown.
+		TotalSize int64
+
+		// NotModified is true if OSS responds with 304 Not Modified to a
+		// conditional request (see WithIfModifiedSince), nothing is written
+		// to the response body in this case.
+		NotModified bool
+
+		// StringToSign is the string signed by the client, compare it with
+		// the one in the SignatureDoesNotMatch error to find out why the
+		// signature is rejected.
+		StringToSign string
+
 		client *Client
 		ctx    context.Context
+		creds  *Credentials
 
 		remote      string
-		canonRes    string
 		queries     url.Values
+		header      http.Header
 		contentType string
 		method      string
 		date        string
 		contentMd5  string
+		autoMd5     bool
+		maxSize     int64
+		spoolLimit  int64
 
-		reqBody  io.Reader
-		respBody io.Writer
+		reqBody       io.Reader
+		reqBodyLength int64
+		respBody      io.Writer
+		reqCrc64      hash.Hash64
+		respCrc64     hash.Hash64
 
-		async bool
+		async         bool
+		decompress    bool
+		preserveMtime bool
+		mtime         time.Time
+
+		detectContentType bool
+
+		// headers of WithHeaders, merged before the request is signed
+		extraHeader http.Header
 	}
 
+	// Logger logs messages of a client.
+	Logger interface {
+		Printf(format string, v ...interface{})
+	}
+
+	// Option sets optional parameters of a request. Options are passed to
+	// upload or download methods, for example WithCallback.
+	Option func(*Request)
+
 	Directory struct {
 		Name string `xml:"Prefix"`
 	}
@@ -60,6 +248,25 @@ type (
 		LastModified string
 		ETag         string
 		Size         int64
+		StorageClass string
+		Owner        Owner
+	}
+
+	Owner struct {
+		ID          string
+		DisplayName string
+	}
+
+	// DeleteResult contains keys deleted and keys failed to be deleted.
+	DeleteResult struct {
+		Deleted []string
+		Errors  []DeleteError
+	}
+
+	DeleteError struct {
+		Key     string
+		Code    string
+		Message string
 	}
 
 	ListResult struct {
@@ -68,26 +275,46 @@ type (
 		Dirs   []Directory
 	}
 
+	// FileInfo contains metadata of a remote file returned by Stat.
+	FileInfo struct {
+		Name         string
+		Size         int64
+		ContentType  string
+		ETag         string
+		LastModified time.Time
+		StorageClass string
+		VersionId    string
+		Meta         map[string]string
+
+		// CRC64 is the CRC64 (ECMA) of the file, see NewCRC64. It is 0 if
+		// OSS does not return it, for example for old files.
+		CRC64 uint64
+
+		// Expiration is the time the file will be deleted by a lifecycle
+		// rule of the bucket whose ID is ExpirationRuleId, it is zero if no
+		// rule applies. Expires is the Expires header of the file.
+		Expiration       time.Time
+		ExpirationRuleId string
+		Expires          time.Time
+	}
+
+	// ImageInfo is the info of an image returned by ImageInfo. Orientation
+	// is the EXIF orientation from 1 to 8 (0 if unknown), which can be used
+	// to rotate the image. FrameCount is greater than 1 for animated images
+	// like GIF. Fields contains all fields returned by OSS, including the
+	// ones above and EXIF fields like DateTimeOriginal and Model.
 	ImageInfo struct {
-		Size   int64
-		Format string
-		Width  int
-		Height int
-	}
-
-	imageInfo struct {
-		Size struct {
-			Value string `json:"value"`
-		} `json:"FileSize"`
-		Format struct {
-			Value string `json:"value"`
-		} `json:"Format"`
-		Width struct {
-			Value string `json:"value"`
-		} `json:"ImageWidth"`
-		Height struct {
-			Value string `json:"value"`
-		} `json:"ImageHeight"`
+		Size        int64
+		Format      string
+		Width       int
+		Height      int
+		Orientation int
+		FrameCount  int
+		Fields      map[string]string
+	}
+
+	imageInfo map[string]struct {
+		Value string `json:"value"`
 	}
 
 	responseError struct {
@@ -97,29 +324,52 @@ type (
 		RequestId      string   `xml:"RequestId"`
 		HostId         string   `xml:"HostId"`
 		OSSAccessKeyId string   `xml:"OSSAccessKeyId"`
+		Endpoint       string   `xml:"Endpoint"`
+		ServerTime     string   `xml:"ServerTime"`
+
+		StringToSign      string `xml:"StringToSign"`
+		StringToSignBytes string `xml:"StringToSignBytes"`
+		SignatureProvided string `xml:"SignatureProvided"`
 	}
 
 	fileList struct {
-		Name        string
-		Prefix      string
-		Marker      string
-		MaxKeys     int
-		Delimiter   string
-		IsTruncated bool
-		NextMarker  string
-		Files       []File      `xml:"Contents"`
-		Directories []Directory `xml:"CommonPrefixes"`
+		Name         string
+		Prefix       string
+		Marker       string
+		MaxKeys      int
+		Delimiter    string
+		IsTruncated  bool
+		NextMarker   string
+		EncodingType string
+		Files        []File      `xml:"Contents"`
+		Directories  []Directory `xml:"CommonPrefixes"`
 	}
 
 	keyOnly struct {
 		Key string `xml:"Key"`
 	}
 
+	countingReader struct {
+		io.Reader
+		n *int64
+	}
+
+	countingWriter struct {
+		io.Writer
+		n *int64
+	}
+
 	deleteReq struct {
 		XMLName xml.Name  `xml:"Delete"`
 		Quiet   bool      `xml:"Quiet"`
 		Files   []keyOnly `xml:"Object"`
 	}
+
+	deleteResp struct {
+		XMLName xml.Name      `xml:"DeleteResult"`
+		Deleted []keyOnly     `xml:"Deleted"`
+		Errors  []DeleteError `xml:"Error"`
+	}
 )
 
 func (c *Client) Exists(remote string) (bool, *Request, error) {
@@ -140,6 +390,75 @@ func (c *Client) ExistsWithContext(ctx context.Context, remote string) (exists b
 	return
 }
 
+// ExistsWithSize wraps ExistsWithSizeWithContext using context.Background.
+func (c *Client) ExistsWithSize(remote string) (bool, int64, error) {
+	return c.ExistsWithSizeWithContext(context.Background(), remote)
+}
+
+// ExistsWithSizeWithContext is like ExistsWithContext but also returns the
+// size of the file from the same HEAD request. If the file does not exist,
+// false and 0 are returned without error.
+func (c *Client) ExistsWithSizeWithContext(ctx context.Context, remote string) (exists bool, size int64, err error) {
+	var req *Request
+	exists, req, err = c.ExistsWithContext(ctx, remote)
+	if err == nil && exists {
+		size = req.Response.ContentLength
+	}
+	return
+}
+
+// Stat wraps StatWithContext using context.Background.
+func (c *Client) Stat(remote string, options ...Option) (*FileInfo, *Request, error) {
+	return c.StatWithContext(context.Background(), remote, options...)
+}
+
+// StatWithContext gets metadata of remote file using a HEAD request. Unlike
+// Exists, an error is returned if the file does not exist.
+func (c *Client) StatWithContext(ctx context.Context, remote string, options ...Option) (info *FileInfo, req *Request, err error) {
+	req = &Request{
+		client: c,
+		ctx:    ctx,
+		remote: remote,
+		method: "HEAD",
+	}
+	for _, option := range options {
+		option(req)
+	}
+	err = req.do()
+	if err == nil && req.Response.StatusCode == 404 {
+		err = &Error{
+			StatusCode: 404,
+			Code:       "NoSuchKey",
+			RequestId:  req.Response.Header.Get("x-oss-request-id"),
+		}
+	}
+	if err != nil {
+		return
+	}
+	header := req.Response.Header
+	lastModified, _ := http.ParseTime(header.Get("Last-Modified"))
+	info = &FileInfo{
+		Name:         strings.TrimPrefix(remote, "/"),
+		Size:         req.Response.ContentLength,
+		ContentType:  header.Get("Content-Type"),
+		ETag:         header.Get("ETag"),
+		LastModified: lastModified,
+		StorageClass: header.Get("x-oss-storage-class"),
+		VersionId:    header.Get("x-oss-version-id"),
+		Meta:         map[string]string{},
+	}
+	info.Expiration, info.ExpirationRuleId = parseExpiration(header.Get("x-oss-expiration"))
+	info.Expires, _ = http.ParseTime(header.Get("Expires"))
+	info.CRC64, _ = strconv.ParseUint(header.Get("x-oss-hash-crc64ecma"), 10, 64)
+	for key := range header {
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, metaPrefix) {
+			info.Meta[strings.TrimPrefix(key, metaPrefix)] = header.Get(key)
+		}
+	}
+	return
+}
+
 func (c *Client) ImageInfo(remote string) (*ImageInfo, *Request, error) {
 	return c.ImageInfoWithContext(context.Background(), remote)
 }
@@ -162,14 +481,23 @@ func (c *Client) ImageInfoWithContext(ctx context.Context, remote string) (info
 		if err != nil {
 			return
 		}
-		size, _ := strconv.ParseInt(imgInfo.Size.Value, 10, 64)
-		width, _ := strconv.Atoi(imgInfo.Width.Value)
-		height, _ := strconv.Atoi(imgInfo.Height.Value)
+		fields := map[string]string{}
+		for key, field := range imgInfo {
+			fields[key] = field.Value
+		}
+		size, _ := strconv.ParseInt(fields["FileSize"], 10, 64)
+		width, _ := strconv.Atoi(fields["ImageWidth"])
+		height, _ := strconv.Atoi(fields["ImageHeight"])
+		orientation, _ := strconv.Atoi(fields["Orientation"])
+		frameCount, _ := strconv.Atoi(fields["FrameCount"])
 		info = &ImageInfo{
-			Size:   size,
-			Format: imgInfo.Format.Value,
-			Width:  width,
-			Height: height,
+			Size:        size,
+			Format:      fields["Format"],
+			Width:       width,
+			Height:      height,
+			Orientation: orientation,
+			FrameCount:  frameCount,
+			Fields:      fields,
 		}
 	}
 	return
@@ -186,8 +514,23 @@ func (c *Client) ImageInfoWithContext(ctx context.Context, remote string) (info
 //  	[]string{"starts-with", "$content-type", "application/"},
 //  	map[string]string{"x-oss-object-acl": "public-read"},
 //  )
+// A Callback can also be provided in "extraConditions", its "callback" field
+// (and custom variable fields) will be added to the policy and the returned
+// fields. Similarly, a SuccessAction can be provided to redirect the browser
+// or to change the status code after the file is uploaded. The conditions are
+// not validated, use ValidateConditions or Form to check them.
+// If CredentialsProvider returns an error, nil is returned, use Form to get
+// the error.
 // For more info, visit https://help.aliyun.com/document_detail/31988.html#title-5go-s2f-dnw
 func (c *Client) PostForm(key string, maxSize int64, duration time.Duration, extraConditions ...interface{}) map[string]string {
+	creds, err := c.credentials(context.Background())
+	if err != nil {
+		return nil
+	}
+	return c.postForm(creds, key, maxSize, duration, extraConditions...)
+}
+
+func (c *Client) postForm(creds Credentials, key string, maxSize int64, duration time.Duration, extraConditions ...interface{}) map[string]string {
 	key = strings.TrimPrefix(key, "/")
 	conditions := []interface{}{
 		map[string]string{"bucket": c.Bucket},
@@ -199,38 +542,60 @@ func (c *Client) PostForm(key string, maxSize int64, duration time.Duration, ext
 	if duration <= 0 {
 		duration = 10 * time.Minute
 	}
+	fields := map[string]string{}
+	if creds.SecurityToken != "" {
+		fields["x-oss-security-token"] = creds.SecurityToken
+		conditions = append(conditions, map[string]string{"x-oss-security-token": creds.SecurityToken})
+	}
 	for _, cond := range extraConditions {
+		if callback, ok := cond.(Callback); ok {
+			fields["callback"] = callback.encode()
+			conditions = append(conditions, map[string]string{"callback": fields["callback"]})
+			for key, value := range callback.Vars {
+				fields[key] = value
+				conditions = append(conditions, map[string]string{key: value})
+			}
+			continue
+		}
+		if action, ok := cond.(SuccessAction); ok {
+			for key, value := range action.fields() {
+				fields[key] = value
+				conditions = append(conditions, map[string]string{key: value})
+			}
+			continue
+		}
 		conditions = append(conditions, cond)
 	}
 	policyJson, _ := json.Marshal(struct {
 		Expiration time.Time   `json:"expiration"`
 		Conditions interface{} `json:"conditions"`
 	}{
-		time.Now().UTC().Round(time.Second).Add(duration),
+		c.now().Round(time.Second).Add(duration),
 		conditions,
 	})
 	policy := base64.StdEncoding.EncodeToString(policyJson)
-	mac := hmac.New(sha1.New, []byte(c.AccessKeySecret))
+	mac := hmac.New(sha1.New, []byte(creds.AccessKeySecret))
 	mac.Write([]byte(policy))
-	return map[string]string{
-		"key":            key,
-		"policy":         policy,
-		"OSSAccessKeyId": c.AccessKeyId,
-		"signature":      base64.StdEncoding.EncodeToString(mac.Sum(nil)),
-	}
+	fields["key"] = key
+	fields["policy"] = policy
+	fields["OSSAccessKeyId"] = creds.AccessKeyId
+	fields["signature"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return fields
 }
 
 // Upload wraps UploadWithContext using context.Background.
-func (c *Client) Upload(remote string, reqBody io.Reader, reqBodyMd5 []byte, contentType string) (*Request, error) {
-	return c.UploadWithContext(context.Background(), remote, reqBody, reqBodyMd5, contentType)
+func (c *Client) Upload(remote string, reqBody io.Reader, reqBodyMd5 []byte, contentType string, options ...Option) (*Request, error) {
+	return c.UploadWithContext(context.Background(), remote, reqBody, reqBodyMd5, contentType, options...)
 }
 
 // Upload creates and executes a upload request for reqBody (io.Reader) to
 // remote path, returns the request and error. reqBodyMd5 can be nil, OSS will
-// run MD5 check if it is provided.  If contentType is empty,
-// "application/octet-stream" will be used. If the body is bytes, use
-// bytes.NewReader. If it is a string, use strings.NewReader.
-func (c *Client) UploadWithContext(ctx context.Context, remote string, reqBody io.Reader, reqBodyMd5 []byte, contentType string) (*Request, error) {
+// run MD5 check if it is provided or computed with the AutoMD5 option.  If
+// contentType is empty, "application/octet-stream" will be used, or it is
+// detected with the WithDetectContentType option. If the body is bytes or a
+// string, use UploadBytes or UploadString. Options like WithCallback can be
+// provided to set optional parameters.
+func (c *Client) UploadWithContext(ctx context.Context, remote string, reqBody io.Reader, reqBodyMd5 []byte, contentType string, options ...Option) (*Request, error) {
 	req := &Request{
 		client:      c,
 		ctx:         ctx,
@@ -240,31 +605,122 @@ func (c *Client) UploadWithContext(ctx context.Context, remote string, reqBody i
 		contentMd5:  base64.StdEncoding.EncodeToString(reqBodyMd5),
 		method:      "PUT",
 	}
-	err := req.do()
-	return req, err
+	return c.upload(req, options)
+}
+
+// UploadFile wraps UploadFileWithContext using context.Background.
+func (c *Client) UploadFile(remote, localPath, contentType string, options ...Option) (*Request, error) {
+	return c.UploadFileWithContext(context.Background(), remote, localPath, contentType, options...)
+}
+
+// UploadFileWithContext uploads local file at localPath to remote path. MD5
+// of the file is computed first and then the file is seeked back to the
+// beginning and streamed as the request body, so the file is never loaded
+// into memory entirely. If contentType is empty, it is determined by the
+// extension of localPath.
+func (c *Client) UploadFileWithContext(ctx context.Context, remote, localPath, contentType string, options ...Option) (*Request, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	md5sum := md5.New()
+	size, err := io.Copy(md5sum, file)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if contentType == "" {
+		contentType = ContentTypeForExtension(filepath.Ext(localPath))
+		if contentType == "application/octet-stream" {
+			// unknown extension, see WithDetectContentType
+			contentType = ""
+		}
+	}
+	req := &Request{
+		client:        c,
+		ctx:           ctx,
+		remote:        remote,
+		reqBody:       file,
+		reqBodyLength: size,
+		contentType:   contentType,
+		contentMd5:    base64.StdEncoding.EncodeToString(md5sum.Sum(nil)),
+		method:        "PUT",
+		mtime:         stat.ModTime(),
+	}
+	return c.upload(req, options)
 }
 
 // Download wraps DownloadWithContext using context.Background.
-func (c *Client) Download(remote string, respBody io.Writer) (*Request, error) {
-	return c.download(context.Background(), remote, respBody, false)
+func (c *Client) Download(remote string, respBody io.Writer, options ...Option) (*Request, error) {
+	return c.download(context.Background(), remote, respBody, false, options)
 }
 
 // Download creates and executes a download request from remote path to
 // respBody (io.Writer), returns the request and error. You can use
 // bytes.Buffer to download the file to memory. If you want to have more than
-// one destination, use io.MultiWriter.
-func (c *Client) DownloadWithContext(ctx context.Context, remote string, respBody io.Writer) (*Request, error) {
-	return c.download(ctx, remote, respBody, false)
+// one destination, use io.MultiWriter. Options like WithVersionId can be
+// provided to set optional parameters. Files stored with Content-Encoding:
+// gzip are written as stored (compressed) unless WithDecompress is given.
+func (c *Client) DownloadWithContext(ctx context.Context, remote string, respBody io.Writer, options ...Option) (*Request, error) {
+	return c.download(ctx, remote, respBody, false, options)
+}
+
+// DownloadFile wraps DownloadFileWithContext using context.Background.
+func (c *Client) DownloadFile(remote, localPath string, options ...Option) (*Request, error) {
+	return c.DownloadFileWithContext(context.Background(), remote, localPath, options...)
+}
+
+// DownloadFileWithContext downloads remote file to localPath. Parent
+// directories are created if they don't exist. The file is first downloaded
+// to a temporary file in the same directory and then renamed to localPath on
+// success, so an interrupted download never leaves a partial file at
+// localPath.
+func (c *Client) DownloadFileWithContext(ctx context.Context, remote, localPath string, options ...Option) (*Request, error) {
+	dir := filepath.Dir(localPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(localPath)+".*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	req, err := c.download(ctx, remote, tmp, false, options)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || req.NotModified {
+		// keep the existing file if it is not modified
+		return req, err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return req, err
+	}
+	if req.preserveMtime {
+		if mtime, err := parseMtime(req.Response.Header.Get(metaPrefix + mtimeMetaKey)); err == nil {
+			if err := os.Chtimes(tmp.Name(), mtime, mtime); err != nil {
+				return req, err
+			}
+		}
+	}
+	return req, os.Rename(tmp.Name(), localPath)
 }
 
 // DownloadAsync wraps DownloadAsyncWithContext using context.Background.
-func (c *Client) DownloadAsync(remote string, respBody io.Writer) (*Request, error) {
-	return c.download(context.Background(), remote, respBody, true)
+func (c *Client) DownloadAsync(remote string, respBody io.Writer, options ...Option) (*Request, error) {
+	return c.download(context.Background(), remote, respBody, true, options)
 }
 
 // DownloadAsync is like Download but won't wait till download is complete.
-func (c *Client) DownloadAsyncWithContext(ctx context.Context, remote string, respBody io.Writer) (*Request, error) {
-	return c.download(ctx, remote, respBody, true)
+func (c *Client) DownloadAsyncWithContext(ctx context.Context, remote string, respBody io.Writer, options ...Option) (*Request, error) {
+	return c.download(ctx, remote, respBody, true, options)
 }
 
 // Delete wraps DeleteWithContext using context.Background.
@@ -273,49 +729,45 @@ func (c *Client) Delete(remotes ...string) error {
 }
 
 // Delete creates and executes a delete request for multiple remote keys
-// (paths) at the same time.
+// (paths) at the same time. If there are more than 1000 keys, they are
+// deleted in batches of 1000 keys, see DeleteConcurrency.
 func (c *Client) DeleteWithContext(ctx context.Context, remotes ...string) error {
-	var reqBody bytes.Buffer
-	reqBody.WriteString(xml.Header)
-	files := []keyOnly{}
-	for _, remote := range remotes {
-		files = append(files, keyOnly{
-			Key: strings.TrimPrefix(remote, "/"),
-		})
-	}
-	if err := xml.NewEncoder(&reqBody).Encode(deleteReq{
-		Quiet: true,
-		Files: files,
-	}); err != nil {
-		return err
-	}
-	md5sum := md5.New()
-	md5sum.Write(reqBody.Bytes())
-	req := &Request{
-		client:     c,
-		ctx:        ctx,
-		remote:     "/?delete",
-		reqBody:    &reqBody,
-		contentMd5: base64.StdEncoding.EncodeToString(md5sum.Sum(nil)),
-		method:     "POST",
-	}
-	err := req.do()
+	_, err := c.delete(ctx, remotes, true)
 	return err
 }
 
+// DeleteVerbose wraps DeleteVerboseWithContext using context.Background.
+func (c *Client) DeleteVerbose(remotes ...string) (DeleteResult, error) {
+	return c.DeleteVerboseWithContext(context.Background(), remotes...)
+}
+
+// DeleteVerboseWithContext is like DeleteWithContext but returns which keys
+// are deleted and which keys failed to be deleted with their error codes.
+func (c *Client) DeleteVerboseWithContext(ctx context.Context, remotes ...string) (DeleteResult, error) {
+	return c.delete(ctx, remotes, false)
+}
+
 // List wraps ListWithContext using context.Background.
 func (c *Client) List(prefix string, recursive bool) (ListResult, error) {
 	return c.ListWithContext(context.Background(), prefix, recursive)
 }
 
 // List creates and executes a list request for remote files and directories
-// under prefix, recursively if recursive is set to true.
+// under prefix, recursively if recursive is set to true. Prefix is always
+// treated as a directory, so "foo" and "foo/" are the same. If recursive is
+// false, Files contains only the files directly under prefix and Dirs
+// contains the sub-directories of prefix.
 func (c *Client) ListWithContext(ctx context.Context, prefix string, recursive bool) (result ListResult, err error) {
 	req := &Request{
 		client: c,
 		ctx:    ctx,
 	}
-	err = req.list(prefix, "", &result, recursive)
+	err = req.list(prefix, "", recursive, func(page ListResult) error {
+		result.Files = append(result.Files, page.Files...)
+		result.Dirs = append(result.Dirs, page.Dirs...)
+		result.Prefix = page.Prefix
+		return nil
+	})
 	return
 }
 
@@ -327,7 +779,96 @@ func (c *Client) URL(remote string) string {
 	return strings.TrimSuffix(c.Prefix, "/") + remote
 }
 
-func (c *Client) download(ctx context.Context, remote string, respBody io.Writer, async bool) (*Request, error) {
+func (c *Client) upload(req *Request, options []Option) (*Request, error) {
+	for _, option := range options {
+		option(req)
+	}
+	if req.preserveMtime && !req.mtime.IsZero() {
+		req.setHeader(metaPrefix+mtimeMetaKey, formatMtime(req.mtime))
+	}
+	if c.DryRun {
+		c.logf("dry run: upload %s", req.URL())
+		return req, nil
+	}
+	if req.spoolLimit > 0 {
+		cleanup, err := req.spoolBody()
+		if err != nil {
+			return req, err
+		}
+		defer cleanup()
+	}
+	if req.detectContentType && req.contentType == "" {
+		if err := req.detectBodyContentType(); err != nil {
+			return req, err
+		}
+	}
+	if req.autoMd5 && req.contentMd5 == "" {
+		if err := req.computeMd5(); err != nil {
+			return req, err
+		}
+	}
+	if c.VerifyCRC64 {
+		req.reqCrc64 = NewCRC64()
+	}
+	err := req.do()
+	if err == nil && req.reqCrc64 != nil {
+		err = req.verifyCrc64(req.reqCrc64)
+	}
+	return req, err
+}
+
+func (c *Client) deleteBatch(ctx context.Context, remotes []string, quiet bool) (result DeleteResult, err error) {
+	if c.DryRun {
+		for _, remote := range remotes {
+			c.logf("dry run: delete %s", c.URL(remote))
+			if !quiet {
+				result.Deleted = append(result.Deleted, strings.TrimPrefix(remote, "/"))
+			}
+		}
+		return
+	}
+	var reqBody bytes.Buffer
+	reqBody.WriteString(xml.Header)
+	files := []keyOnly{}
+	for _, remote := range remotes {
+		files = append(files, keyOnly{
+			Key: strings.TrimPrefix(remote, "/"),
+		})
+	}
+	if err = xml.NewEncoder(&reqBody).Encode(deleteReq{
+		Quiet: quiet,
+		Files: files,
+	}); err != nil {
+		return
+	}
+	md5sum := md5.New()
+	md5sum.Write(reqBody.Bytes())
+	var response bytes.Buffer
+	req := &Request{
+		client:     c,
+		ctx:        ctx,
+		remote:     "/",
+		queries:    url.Values{"delete": []string{""}},
+		reqBody:    bytes.NewReader(reqBody.Bytes()),
+		contentMd5: base64.StdEncoding.EncodeToString(md5sum.Sum(nil)),
+		method:     "POST",
+		respBody:   &response,
+	}
+	if err = req.do(); err != nil || quiet {
+		return
+	}
+	var resp deleteResp
+	if err = xml.NewDecoder(&response).Decode(&resp); err != nil {
+		return
+	}
+	for _, file := range resp.Deleted {
+		result.Deleted = append(result.Deleted, file.Key)
+	}
+	result.Errors = resp.Errors
+	return
+}
+
+func (c *Client) download(ctx context.Context, remote string, respBody io.Writer, async bool, options []Option) (*Request, error) {
 	req := &Request{
 		client:   c,
 		ctx:      ctx,
@@ -336,7 +877,17 @@ func (c *Client) download(ctx context.Context, remote string, respBody io.Writer
 		respBody: respBody,
 		async:    async,
 	}
+	for _, option := range options {
+		option(req)
+	}
+	if c.VerifyCRC64 && !async && !req.decompress && req.header.Get("Range") == "" {
+		req.respCrc64 = NewCRC64()
+		req.respBody = io.MultiWriter(respBody, req.respCrc64)
+	}
 	err := req.do()
+	if err == nil && req.respCrc64 != nil && req.Response != nil && req.Response.StatusCode == 200 {
+		err = req.verifyCrc64(req.respCrc64)
+	}
 	return req, err
 }
 
@@ -346,61 +897,249 @@ func (req *Request) String() string {
 
 func (req *Request) URL() string {
 	url := strings.TrimSuffix(req.client.Prefix, "/") + req.getRemote()
-	qs := req.queries.Encode()
+	qs := encodeQuery(req.queries)
 	if qs == "" {
 		return url
 	}
 	return url + "?" + qs
 }
 
-func (req *Request) list(prefix string, marker string, result *ListResult, recursive bool) (err error) {
+// requestURL is like URL but uses Endpoint if it is set.
+func (req *Request) requestURL() string {
+	url := req.client.baseURL() + req.getRemote()
+	qs := encodeQuery(req.queries)
+	if qs == "" {
+		return url
+	}
+	return url + "?" + qs
+}
+
+// encodeQuery is like url.Values.Encode but has no "=" for subresources
+// without values, for example "?acl" instead of "?acl=".
+func encodeQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		for _, v := range values[k] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k))
+			if v != "" || !signedSubresources[k] {
+				b.WriteByte('=')
+				b.WriteString(url.QueryEscape(v))
+			}
+		}
+	}
+	return b.String()
+}
+
+func (c *Client) now() time.Time {
+	return time.Now().UTC().Add(c.TimeOffset)
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// CloseIdleConnections closes idle keep-alive connections of HTTPClient. If
+// HTTPClient is nil, idle connections of http.DefaultClient, which may be
+// shared with other code, are closed.
+func (c *Client) CloseIdleConnections() {
+	c.httpClient().CloseIdleConnections()
+}
+
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, v...)
+	}
+}
+
+func (c *Client) baseURL() string {
+	if c.Endpoint == "" {
+		return strings.TrimSuffix(c.Prefix, "/")
+	}
+	endpoint := strings.TrimSuffix(c.Endpoint, "/")
+	if c.PathStyle {
+		return endpoint + "/" + c.Bucket
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return endpoint
+	}
+	u.Host = c.Bucket + "." + u.Host
+	return u.String()
+}
+
+// list sends list requests starting after marker and calls fn with the
+// files and directories of each page, until all pages are listed or fn
+// returns an error.
+func (req *Request) list(prefix string, marker string, recursive bool, fn func(ListResult) error) (err error) {
 	req.remote = "/"
-	req.canonRes = "/"
+	prefix = listPrefix(prefix)
+	for {
+		req.queries = url.Values{}
+		req.queries.Set("max-keys", "1000")
+		req.queries.Set("prefix", prefix)
+		req.queries.Set("marker", marker)
+		req.queries.Set("encoding-type", "url")
+		if !recursive {
+			req.queries.Set("delimiter", "/")
+		}
+		req.method = "GET"
+		var response bytes.Buffer
+		req.respBody = &response
+		err = req.do()
+		if err != nil {
+			return
+		}
+		var list fileList
+		if err := xml.NewDecoder(&response).Decode(&list); err != nil {
+			return err
+		}
+		if list.EncodingType == "url" {
+			if err := decodeListKeys(list.Files, list.Directories, &list.Prefix, &list.NextMarker); err != nil {
+				return err
+			}
+		}
+		page := ListResult{
+			Prefix: list.Prefix,
+			Dirs:   list.Directories,
+		}
+		for _, file := range list.Files {
+			if !recursive && file.Name == prefix {
+				// skip the directory marker object itself
+				continue
+			}
+			page.Files = append(page.Files, file)
+		}
+		if err = fn(page); err != nil || !list.IsTruncated {
+			return
+		}
+		marker = list.NextMarker
+	}
+}
+
+// listPrefix returns prefix as a directory with trailing slash, or empty for
+// the root directory.
+func listPrefix(prefix string) string {
 	prefix = strings.Trim(prefix, "/") + "/"
 	if prefix == "/" {
-		prefix = ""
+		return ""
 	}
-	req.queries = url.Values{}
-	req.queries.Set("max-keys", "1000")
-	req.queries.Set("prefix", prefix)
-	req.queries.Set("marker", marker)
-	if !recursive {
-		req.queries.Set("delimiter", "/")
+	return prefix
+}
+
+// send sends the request once, see do.
+func (req *Request) send() (err error) {
+	ctx := req.ctx
+	cancel := func() {}
+	if req.client.DefaultTimeout > 0 {
+		if _, ok := ctx.Deadline(); !ok {
+			ctx, cancel = context.WithTimeout(ctx, req.client.DefaultTimeout)
+		}
 	}
-	req.method = "GET"
-	var response bytes.Buffer
-	req.respBody = &response
-	err = req.do()
-	var list fileList
-	if err := xml.NewDecoder(&response).Decode(&list); err != nil {
-		return err
+	requestURL := req.requestURL()
+	start := time.Now()
+	var received int64
+	done := func(err error) {
+		if req.client.OnRequestDone != nil {
+			status := 0
+			if req.Response != nil {
+				status = req.Response.StatusCode
+			}
+			req.client.OnRequestDone(req.method, requestURL, status, req.BytesSent+received, time.Since(start), err)
+		}
 	}
-	result.Files = append(result.Files, list.Files...)
-	result.Dirs = append(result.Dirs, list.Directories...)
-	result.Prefix = list.Prefix
-	if list.IsTruncated {
-		err = req.list(prefix, list.NextMarker, result, recursive)
+	if req.client.OnRequestStart != nil {
+		req.client.OnRequestStart(req.method, requestURL)
+	}
+	async := false
+	defer func() {
+		if !async {
+			cancel()
+			done(err)
+		}
+	}()
+	reqBody := req.reqBody
+	bodyLength, known := req.bodyLength()
+	if known && bodyLength == 0 && reqBody != nil {
+		// send Content-Length: 0 instead of an empty chunked body
+		reqBody = http.NoBody
 	}
-	return
-}
-
-func (req *Request) do() (err error) {
 	var httpReq *http.Request
-	httpReq, err = http.NewRequestWithContext(req.ctx, req.method, req.URL(), req.reqBody)
+	httpReq, err = http.NewRequestWithContext(ctx, req.method, requestURL, reqBody)
+	if err != nil {
+		return
+	}
+	var creds Credentials
+	creds, err = req.client.credentials(ctx)
 	if err != nil {
 		return
 	}
-	if req.contentType == "" {
+	req.creds = &creds
+	if creds.SecurityToken != "" {
+		req.setHeader("x-oss-security-token", creds.SecurityToken)
+	}
+	if bodyLength > 0 {
+		httpReq.ContentLength = bodyLength
+	}
+	req.mergeHeaders()
+	if req.contentType == "" && (req.method == "PUT" || req.method == "POST") {
 		req.contentType = "application/octet-stream"
 	}
-	httpReq.Header.Set("Content-Type", req.contentType)
-	req.date = time.Now().UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT") // don't use time.RFC1123
+	if req.contentType != "" {
+		// requests without body (like GET) have no content type, which is
+		// also empty in the signature
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	req.date = req.client.now().Format("Mon, 02 Jan 2006 15:04:05 GMT") // don't use time.RFC1123
 	httpReq.Header.Set("Date", req.date)
 	if req.contentMd5 != "" {
 		httpReq.Header.Set("Content-MD5", req.contentMd5)
 	}
-	httpReq.Header.Set("Authorization", fmt.Sprintf("OSS %s:%s", req.client.AccessKeyId, req.signature()))
-	client := &http.Client{}
+	if req.useV4() {
+		req.setV4Headers()
+	}
+	req.translateHeaders()
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+	if httpReq.Header.Get("Accept-Encoding") == "" {
+		// otherwise http.Transport requests gzip and decompresses the body
+		// of files stored with Content-Encoding: gzip, see WithDecompress
+		httpReq.Header.Set("Accept-Encoding", "identity")
+	}
+	httpReq.Header.Set("Authorization", req.authorization())
+	if httpReq.Body != nil && httpReq.Body != http.NoBody {
+		// replace body after content length is determined
+		body := req.reqBody
+		if req.reqCrc64 != nil {
+			body = io.TeeReader(body, req.reqCrc64)
+		}
+		if limiter := req.client.rateLimiter(); limiter != nil {
+			body = &rateLimitedReader{ctx, body, limiter}
+		}
+		httpReq.Body = ioutil.NopCloser(&countingReader{body, &req.BytesSent})
+	}
+	client := *req.client.httpClient()
+	if client.CheckRedirect == nil {
+		// don't follow redirects (like 307 to the endpoint of another
+		// region), the signature doesn't match on another host, see
+		// WrongRegionError
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	req.Response = nil
+	req.BytesSent = 0
 	var resp *http.Response
 	resp, err = client.Do(httpReq)
 	if err != nil {
@@ -409,54 +1148,207 @@ func (req *Request) do() (err error) {
 	req.Response = resp
 	cl := resp.ContentLength
 	req.ResponseContentLength = &cl
-	if resp.StatusCode == 200 {
+	req.ETag = resp.Header.Get("ETag")
+	req.TotalSize = -1
+	if resp.StatusCode == 206 {
+		req.TotalSize = parseContentRangeTotal(resp.Header.Get("Content-Range"))
+	} else if resp.StatusCode == 200 {
+		req.TotalSize = resp.ContentLength
+	}
+	if resp.StatusCode == 200 || resp.StatusCode == 204 || resp.StatusCode == 206 {
 		if req.respBody == nil {
 			resp.Body.Close()
 			return
 		}
+		if req.maxSize > 0 && resp.ContentLength > req.maxSize {
+			resp.Body.Close()
+			err = ErrTooLarge
+			return
+		}
+		var respBody io.Writer = &countingWriter{req.respBody, &received}
+		if req.maxSize > 0 {
+			respBody = &maxSizeWriter{respBody, req.maxSize}
+		}
+		if limiter := req.client.rateLimiter(); limiter != nil {
+			respBody = &rateLimitedWriter{ctx, respBody, limiter}
+		}
+		var body io.Reader = resp.Body
+		if req.decompress && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+			body = &gzipReader{r: resp.Body}
+		}
 		if req.async {
+			// cancel after the body is copied
+			async = true
 			go func() {
+				defer cancel()
 				defer resp.Body.Close()
-				io.Copy(req.respBody, resp.Body)
+				_, err := io.Copy(respBody, body)
+				done(err)
 			}()
 			return
 		}
 		defer resp.Body.Close()
-		io.Copy(req.respBody, resp.Body)
+		_, err = io.Copy(respBody, body)
 		return
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == 404 && req.method == "HEAD" {
 		return
 	}
+	if resp.StatusCode == 304 {
+		req.NotModified = true
+		return
+	}
 	var body []byte
 	body, err = ioutil.ReadAll(resp.Body)
 	if err == nil {
+		respErr := &Error{
+			StatusCode: resp.StatusCode,
+			Body:       body,
+		}
 		errResp := responseError{}
-		err = xml.Unmarshal(body, &errResp)
-		if err == nil && len(errResp.Message) > 0 {
-			err = errors.New(errResp.Message)
+		if xml.Unmarshal(body, &errResp) == nil && len(errResp.Message) > 0 {
+			respErr.Code = errResp.Code
+			respErr.Message = errResp.Message
+			respErr.RequestId = errResp.RequestId
+			respErr.HostId = errResp.HostId
+			respErr.ServerTime, _ = time.Parse(time.RFC3339, errResp.ServerTime)
 		} else {
-			err = errors.New(strings.TrimSpace(string(body)))
+			respErr.Message = strings.TrimSpace(string(body))
+			respErr.RequestId = resp.Header.Get("x-oss-request-id")
+		}
+		err = respErr
+		if respErr.Code == "SignatureDoesNotMatch" {
+			req.client.logf("signature does not match, string to sign: %q", req.StringToSign)
+			err = &SignatureError{
+				ServerStringToSign: errResp.stringToSign(),
+				ClientStringToSign: req.StringToSign,
+				SignatureProvided:  errResp.SignatureProvided,
+				Err:                respErr,
+			}
+		}
+		endpoint := errResp.Endpoint
+		if endpoint == "" && resp.StatusCode/100 == 3 {
+			endpoint = redirectEndpoint(resp.Header.Get("Location"), req.client.Bucket)
+		}
+		if endpoint != "" {
+			err = &WrongRegionError{
+				Endpoint: endpoint,
+				Err:      respErr,
+			}
 		}
 	}
 	return
 }
 
-func (req *Request) queryString() string {
-	if len(req.queries) == 0 {
+// redirectEndpoint returns the endpoint (like oss-cn-beijing.aliyuncs.com)
+// of the host in location of a redirect response, without the bucket name.
+func redirectEndpoint(location, bucket string) string {
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
 		return ""
 	}
-	var b strings.Builder
-	b.WriteString("?")
+	return strings.TrimPrefix(u.Host, bucket+".")
+}
+
+// stringToSign returns StringToSign, or if it is empty, the string decoded
+// from StringToSignBytes like "47 45 54 0A".
+func (e responseError) stringToSign() string {
+	if e.StringToSign != "" || e.StringToSignBytes == "" {
+		return e.StringToSign
+	}
+	b, err := hex.DecodeString(strings.Join(strings.Fields(e.StringToSignBytes), ""))
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// bodyLength returns the number of bytes of the request body if it is known,
+// that is reqBodyLength or, if the body is an io.Seeker (like *os.File), the
+// number of bytes from the current position to the end.
+func (req *Request) bodyLength() (int64, bool) {
+	if req.reqBodyLength > 0 {
+		return req.reqBodyLength, true
+	}
+	seeker, ok := req.reqBody.(io.Seeker)
+	if !ok {
+		return 0, false
+	}
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false
+	}
+	if _, err := seeker.Seek(current, io.SeekStart); err != nil {
+		return 0, false
+	}
+	return end - current, true
+}
+
+func (req *Request) verifyCrc64(sum hash.Hash64) error {
+	remote := req.Response.Header.Get("x-oss-hash-crc64ecma")
+	if remote == "" {
+		return nil
+	}
+	local := strconv.FormatUint(sum.Sum64(), 10)
+	if local != remote {
+		return fmt.Errorf("crc64 mismatch: local %s, remote %s", local, remote)
+	}
+	return nil
+}
+
+// signedSubresources are query parameters that are included in the
+// canonicalized resource, other query parameters are not signed.
+var signedSubresources = map[string]bool{
+	"acl": true, "append": true, "asyncFetch": true, "bucketInfo": true,
+	"callback": true, "callback-var": true, "cname": true, "comp": true,
+	"continuation-token": true, "cors": true, "delete": true,
+	"encryption": true, "endTime": true, "img": true, "inventory": true,
+	"inventoryId": true, "lifecycle": true, "live": true, "location": true,
+	"logging": true, "objectMeta": true, "partNumber": true, "policy": true,
+	"position": true, "qos": true, "qosInfo": true, "referer": true,
+	"regionList": true, "replication": true, "replicationLocation": true,
+	"replicationProgress": true, "requestPayment": true, "restore": true,
+	"response-cache-control": true, "response-content-disposition": true,
+	"response-content-encoding": true, "response-content-language": true,
+	"response-content-type": true, "response-expires": true,
+	"security-token": true, "sequential": true, "startTime": true,
+	"stat": true, "status": true, "style": true, "styleName": true,
+	"symlink": true, "tagging": true, "transferAcceleration": true,
+	"uploadId": true, "uploads": true, "versionId": true, "versioning": true,
+	"versions": true, "vod": true, "website": true, "worm": true,
+	"wormExtend": true, "wormId": true, "x-oss-process": true,
+	"x-oss-request-payer": true, "x-oss-traffic-limit": true,
+}
+
+// queryString returns signed subresources of the request for the
+// canonicalized resource, sorted by name. Values are not escaped and
+// subresources without values have no "=".
+func (req *Request) queryString() string {
+	var keys []string
 	for k := range req.queries {
+		if signedSubresources[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
 		for _, v := range req.queries[k] {
-			if b.Len() > 1 {
+			if b.Len() == 0 {
+				b.WriteByte('?')
+			} else {
 				b.WriteByte('&')
 			}
 			b.WriteString(k)
-			b.WriteByte('=')
-			b.WriteString(v)
+			if v != "" {
+				b.WriteByte('=')
+				b.WriteString(v)
+			}
 		}
 	}
 	return b.String()
@@ -469,11 +1361,89 @@ func (req *Request) getRemote() string {
 	return req.remote
 }
 
+// resourcePath returns the signed path of the request: "/bucket/key" for
+// objects, "/bucket/" for bucket-level requests (remote is "/" or empty) and
+// "/" if the client has no bucket. Bucket-level operations like ?acl,
+// ?location or ?uploads must use remote "/" and set the subresource in
+// queries, so the subresource is signed too.
+func (req *Request) resourcePath() string {
+	if req.client.Bucket == "" {
+		return "/"
+	}
+	return "/" + req.client.Bucket + req.getRemote()
+}
+
 func (req *Request) canonicalizedResource() string {
-	if req.canonRes != "" {
-		return "/" + req.client.Bucket + req.canonRes
+	return req.resourcePath() + req.queryString()
+}
+
+func (c *Client) headerPrefix() string {
+	if c.Flavor == FlavorS3 {
+		return "x-amz-"
 	}
-	return "/" + req.client.Bucket + req.getRemote() + req.queryString()
+	return "x-oss-"
+}
+
+// translateHeaders renames x-oss-* headers to x-amz-* headers for
+// S3-compatible services.
+func (req *Request) translateHeaders() {
+	if req.client.Flavor != FlavorS3 {
+		return
+	}
+	for key, values := range req.header {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "x-oss-") {
+			delete(req.header, key)
+			req.header[http.CanonicalHeaderKey("x-amz-"+strings.TrimPrefix(lower, "x-oss-"))] = values
+		}
+	}
+}
+
+// setQuery sets query parameter of the request, signed subresources will be
+// signed.
+func (req *Request) setQuery(key, value string) {
+	if req.queries == nil {
+		req.queries = url.Values{}
+	}
+	req.queries.Set(key, value)
+}
+
+// setHeader sets header of the request, x-oss-* headers will be signed.
+func (req *Request) setHeader(key, value string) {
+	if req.header == nil {
+		req.header = http.Header{}
+	}
+	req.header.Set(key, value)
+}
+
+func (req *Request) canonicalizedOSSHeaders() string {
+	prefix := req.client.headerPrefix()
+	var keys []string
+	for key := range req.header {
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteByte(':')
+		b.WriteString(strings.TrimSpace(req.header.Get(key)))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func (req *Request) authorization() string {
+	if req.useV4() {
+		return req.authorizationV4()
+	}
+	if req.client.Flavor == FlavorS3 {
+		return fmt.Sprintf("AWS %s:%s", req.credentials().AccessKeyId, req.signature())
+	}
+	return fmt.Sprintf("OSS %s:%s", req.credentials().AccessKeyId, req.signature())
 }
 
 func (req *Request) signature() string {
@@ -482,9 +1452,22 @@ func (req *Request) signature() string {
 		req.contentMd5,
 		req.contentType,
 		req.date,
-		req.canonicalizedResource(),
+		req.canonicalizedOSSHeaders() + req.canonicalizedResource(),
 	}, "\n")
-	mac := hmac.New(sha1.New, []byte(req.client.AccessKeySecret))
+	req.StringToSign = msg
+	mac := hmac.New(sha1.New, []byte(req.credentials().AccessKeySecret))
 	mac.Write([]byte(msg))
 	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
 }
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	*r.n += int64(n)
+	return
+}
+
+func (w *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = w.Writer.Write(p)
+	*w.n += int64(n)
+	return
+}
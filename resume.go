@@ -0,0 +1,260 @@
+package ossslim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// default part size of DownloadFileResumable and UploadFileResumable
+	defaultResumePartSize = 8 << 20
+
+	// max number of parts of a multipart upload
+	maxParts = 10000
+)
+
+type (
+	// downloadCheckpoint records the completed byte ranges of a resumable
+	// download. It is only valid for the remote file of the same ETag and
+	// size.
+	downloadCheckpoint struct {
+		Remote    string
+		ETag      string
+		Size      int64
+		PartSize  int64
+		Completed []byteRange
+	}
+
+	byteRange struct {
+		Start int64
+		End   int64
+	}
+
+	// uploadCheckpoint records the upload ID and completed parts of a
+	// resumable upload. It is only valid for the local file of the same
+	// size and modification time.
+	uploadCheckpoint struct {
+		Remote   string
+		UploadId string
+		Size     int64
+		ModTime  int64
+		PartSize int64
+		Parts    []Part
+	}
+)
+
+// DownloadFileResumable wraps DownloadFileResumableWithContext using
+// context.Background.
+func (c *Client) DownloadFileResumable(remote, localPath string, partSize int64, options ...Option) (int64, error) {
+	return c.DownloadFileResumableWithContext(context.Background(), remote, localPath, partSize, options...)
+}
+
+// DownloadFileResumableWithContext downloads remote file to localPath in
+// parts of partSize bytes (8 MB if it is not greater than 0) using ranged
+// downloads, returns the size of the file. The file is downloaded to
+// localPath.download first and completed parts are recorded in
+// localPath.checkpoint, so if the download is interrupted, calling it again
+// continues from the parts not yet downloaded. If the ETag or size of the
+// remote file has changed since, the checkpoint is discarded and the file is
+// downloaded again. On success, the file is renamed to localPath and the
+// checkpoint is removed.
+func (c *Client) DownloadFileResumableWithContext(ctx context.Context, remote, localPath string, partSize int64, options ...Option) (int64, error) {
+	if partSize <= 0 {
+		partSize = defaultResumePartSize
+	}
+	info, _, err := c.StatWithContext(ctx, remote, options...)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return 0, err
+	}
+	tmpPath := localPath + ".download"
+	checkpointPath := localPath + ".checkpoint"
+	cp := downloadCheckpoint{
+		Remote:   remote,
+		ETag:     info.ETag,
+		Size:     info.Size,
+		PartSize: partSize,
+	}
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	var old downloadCheckpoint
+	if readCheckpoint(checkpointPath, &old) == nil && old.Remote == cp.Remote &&
+		old.ETag == cp.ETag && old.Size == cp.Size && old.PartSize == cp.PartSize {
+		if _, err := os.Stat(tmpPath); err == nil {
+			cp.Completed = old.Completed
+			flag = os.O_WRONLY
+		}
+	}
+	file, err := os.OpenFile(tmpPath, flag, 0644)
+	if err != nil {
+		return 0, err
+	}
+	completed := map[int64]bool{}
+	for _, r := range cp.Completed {
+		completed[r.Start] = true
+	}
+	for start := int64(0); start < info.Size; start += partSize {
+		if completed[start] {
+			continue
+		}
+		end := start + partSize - 1
+		if end >= info.Size {
+			end = info.Size - 1
+		}
+		opts := make([]Option, 0, len(options)+2)
+		opts = append(opts, options...)
+		opts = append(opts, WithRange(start, end), withIfMatch(info.ETag))
+		req, err := c.DownloadWithContext(ctx, remote, &offsetWriter{file, start}, opts...)
+		if err == nil && req.Response.StatusCode != 206 && (start > 0 || end < info.Size-1) {
+			err = errors.New("range is not supported")
+		}
+		if err == nil {
+			// make sure the part is written before it is recorded
+			err = file.Sync()
+		}
+		if err != nil {
+			file.Close()
+			return 0, err
+		}
+		cp.Completed = append(cp.Completed, byteRange{start, end})
+		if err := writeCheckpoint(checkpointPath, cp); err != nil {
+			file.Close()
+			return 0, err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmpPath, localPath); err != nil {
+		return 0, err
+	}
+	os.Remove(checkpointPath)
+	return info.Size, nil
+}
+
+// UploadFileResumable wraps UploadFileResumableWithContext using
+// context.Background.
+func (c *Client) UploadFileResumable(remote, localPath, contentType string, partSize int64) error {
+	return c.UploadFileResumableWithContext(context.Background(), remote, localPath, contentType, partSize)
+}
+
+// UploadFileResumableWithContext uploads local file to remote path by
+// multipart upload in parts of partSize bytes (8 MB if it is not greater than
+// 0, increased if there would be more than 10000 parts). The upload ID and
+// completed parts are recorded in localPath.upload.checkpoint, so if the
+// upload is interrupted, calling it again continues with the same multipart
+// upload. The recorded parts are checked against ListParts, parts missing on
+// OSS are uploaded again. If the size or modification time of the local file
+// has changed, a new multipart upload is initiated. On success, the
+// checkpoint is removed.
+func (c *Client) UploadFileResumableWithContext(ctx context.Context, remote, localPath, contentType string, partSize int64) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	size := stat.Size()
+	if size == 0 {
+		_, err := c.UploadWithContext(ctx, remote, file, nil, contentType)
+		return err
+	}
+	if partSize <= 0 {
+		partSize = defaultResumePartSize
+	}
+	if minPartSize := (size + maxParts - 1) / maxParts; partSize < minPartSize {
+		partSize = minPartSize
+	}
+	checkpointPath := localPath + ".upload.checkpoint"
+	cp := uploadCheckpoint{
+		Remote:   remote,
+		Size:     size,
+		ModTime:  stat.ModTime().UnixNano(),
+		PartSize: partSize,
+	}
+	var old uploadCheckpoint
+	if readCheckpoint(checkpointPath, &old) == nil && old.UploadId != "" && old.Remote == cp.Remote &&
+		old.Size == cp.Size && old.ModTime == cp.ModTime && old.PartSize == cp.PartSize {
+		parts, err := c.ListPartsWithContext(ctx, remote, old.UploadId)
+		var ossErr *Error
+		if err != nil && !(errors.As(err, &ossErr) && ossErr.Code == "NoSuchUpload") {
+			return err
+		}
+		if err == nil {
+			// keep recorded parts which are still on OSS
+			uploaded := map[int]string{}
+			for _, part := range parts {
+				uploaded[part.PartNumber] = part.ETag
+			}
+			cp.UploadId = old.UploadId
+			for _, part := range old.Parts {
+				if uploaded[part.PartNumber] == part.ETag {
+					cp.Parts = append(cp.Parts, part)
+				}
+			}
+		}
+	}
+	if cp.UploadId == "" {
+		if cp.UploadId, err = c.InitiateMultipartUploadWithContext(ctx, remote, contentType); err != nil {
+			return err
+		}
+		if err := writeCheckpoint(checkpointPath, cp); err != nil {
+			return err
+		}
+	}
+	completed := map[int]bool{}
+	for _, part := range cp.Parts {
+		completed[part.PartNumber] = true
+	}
+	for partNumber, start := 1, int64(0); start < size; partNumber, start = partNumber+1, start+partSize {
+		if completed[partNumber] {
+			continue
+		}
+		n := partSize
+		if start+n > size {
+			n = size - start
+		}
+		etag, err := c.UploadPartWithContext(ctx, remote, cp.UploadId, partNumber, io.NewSectionReader(file, start, n))
+		if err != nil {
+			return err
+		}
+		cp.Parts = append(cp.Parts, Part{PartNumber: partNumber, ETag: etag})
+		if err := writeCheckpoint(checkpointPath, cp); err != nil {
+			return err
+		}
+	}
+	sort.Slice(cp.Parts, func(i, j int) bool {
+		return cp.Parts[i].PartNumber < cp.Parts[j].PartNumber
+	})
+	if err := c.CompleteMultipartUploadWithContext(ctx, remote, cp.UploadId, cp.Parts); err != nil {
+		return err
+	}
+	os.Remove(checkpointPath)
+	return nil
+}
+
+func readCheckpoint(path string, cp interface{}) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, cp)
+}
+
+func writeCheckpoint(path string, cp interface{}) error {
+	content, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,159 @@
+package ossslim
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadFileResumable(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 100))
+	etag := `"foo"`
+	fail := true
+	var ranges []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			ranges = append(ranges, r.Header.Get("Range"))
+			if fail && r.Header.Get("Range") == "bytes=600-899" {
+				w.WriteHeader(500)
+				return
+			}
+		}
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "foo", time.Time{}, bytes.NewReader(content))
+	})
+	localPath := filepath.Join(t.TempDir(), "dir", "foo")
+	if _, err := client.DownloadFileResumable("foo", localPath, 300); err == nil {
+		t.Fatal("download should fail")
+	}
+	if _, err := os.Stat(localPath + ".checkpoint"); err != nil {
+		t.Fatal("checkpoint should exist", err)
+	}
+	fail = false
+	size, err := client.DownloadFileResumable("foo", localPath, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "bytes=0-299,bytes=300-599,bytes=600-899,bytes=600-899,bytes=900-999"
+	if size != 1000 || strings.Join(ranges, ",") != expected {
+		t.Error("wrong size or ranges", size, ranges)
+	}
+	if downloaded, _ := ioutil.ReadFile(localPath); !bytes.Equal(downloaded, content) {
+		t.Error("wrong content")
+	}
+	for _, path := range []string{localPath + ".checkpoint", localPath + ".download"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Error("file should be removed", path)
+		}
+	}
+
+	// changed remote file invalidates the checkpoint
+	fail = true
+	ranges = nil
+	if _, err := client.DownloadFileResumable("foo", localPath, 300); err == nil {
+		t.Fatal("download should fail")
+	}
+	fail = false
+	content = []byte(strings.Repeat("9876543210", 100))
+	etag = `"bar"`
+	if _, err := client.DownloadFileResumable("foo", localPath, 300); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ranges, ",") != "bytes=0-299,bytes=300-599,bytes=600-899,bytes=0-299,bytes=300-599,bytes=600-899,bytes=900-999" {
+		t.Error("wrong ranges", ranges)
+	}
+	if downloaded, _ := ioutil.ReadFile(localPath); !bytes.Equal(downloaded, content) {
+		t.Error("wrong content")
+	}
+}
+
+func TestUploadFileResumable(t *testing.T) {
+	uploads := map[string]map[string]string{}
+	nextId := 0
+	failPart := "3"
+	var requests []string
+	var completed string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		uploadId := q.Get("uploadId")
+		switch {
+		case r.Method == "POST" && q["uploads"] != nil:
+			nextId++
+			uploadId = "u" + strconv.Itoa(nextId)
+			uploads[uploadId] = map[string]string{}
+			requests = append(requests, "initiate "+uploadId)
+			w.Write([]byte("<InitiateMultipartUploadResult><UploadId>" + uploadId + "</UploadId></InitiateMultipartUploadResult>"))
+		case uploads[uploadId] == nil:
+			w.WriteHeader(404)
+			w.Write([]byte("<Error><Code>NoSuchUpload</Code><Message>The specified upload does not exist.</Message></Error>"))
+		case r.Method == "GET":
+			requests = append(requests, "list "+uploadId)
+			var b strings.Builder
+			b.WriteString("<ListPartsResult><IsTruncated>false</IsTruncated>")
+			for partNumber, etag := range uploads[uploadId] {
+				b.WriteString("<Part><PartNumber>" + partNumber + "</PartNumber><ETag>" + etag + "</ETag></Part>")
+			}
+			b.WriteString("</ListPartsResult>")
+			w.Write([]byte(b.String()))
+		case r.Method == "PUT":
+			partNumber := q.Get("partNumber")
+			requests = append(requests, "part "+uploadId+" "+partNumber)
+			if partNumber == failPart {
+				w.WriteHeader(500)
+				return
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			uploads[uploadId][partNumber] = string(body)
+			w.Header().Set("ETag", string(body))
+		case r.Method == "POST":
+			body, _ := ioutil.ReadAll(r.Body)
+			requests = append(requests, "complete "+uploadId)
+			completed = string(body)
+		}
+	})
+	localPath := filepath.Join(t.TempDir(), "foo")
+	if err := ioutil.WriteFile(localPath, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.UploadFileResumable("foo", localPath, "", 3); err == nil {
+		t.Fatal("upload should fail")
+	}
+	failPart = ""
+	if err := client.UploadFileResumable("foo", localPath, "", 3); err != nil {
+		t.Fatal(err)
+	}
+	expected := "initiate u1,part u1 1,part u1 2,part u1 3,list u1,part u1 3,part u1 4,complete u1"
+	if strings.Join(requests, ",") != expected {
+		t.Error("wrong requests", requests)
+	}
+	if !strings.HasSuffix(completed, "<Part><PartNumber>1</PartNumber><ETag>012</ETag></Part>"+
+		"<Part><PartNumber>2</PartNumber><ETag>345</ETag></Part><Part><PartNumber>3</PartNumber><ETag>678</ETag></Part>"+
+		"<Part><PartNumber>4</PartNumber><ETag>9</ETag></Part></CompleteMultipartUpload>") {
+		t.Error("wrong parts", completed)
+	}
+	if _, err := os.Stat(localPath + ".upload.checkpoint"); !os.IsNotExist(err) {
+		t.Error("checkpoint should be removed")
+	}
+
+	// upload aborted on OSS is initiated again
+	requests = nil
+	failPart = "2"
+	if err := client.UploadFileResumable("foo", localPath, "", 3); err == nil {
+		t.Fatal("upload should fail")
+	}
+	delete(uploads, "u2")
+	failPart = ""
+	if err := client.UploadFileResumable("foo", localPath, "", 3); err != nil {
+		t.Fatal(err)
+	}
+	expected = "initiate u2,part u2 1,part u2 2,initiate u3,part u3 1,part u3 2,part u3 3,part u3 4,complete u3"
+	if strings.Join(requests, ",") != expected {
+		t.Error("wrong requests", requests)
+	}
+}
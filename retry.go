@@ -0,0 +1,134 @@
+package ossslim
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	retryBaseDelay = 100 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+)
+
+// do sends the request and retries it up to MaxRetries times if it fails
+// and ShouldRetry returns true and its body can be rewound.
+func (req *Request) do() (err error) {
+	var seeker io.Seeker
+	var start int64
+	if req.reqBody != nil && req.client.MaxRetries > 0 {
+		if s, ok := req.reqBody.(io.Seeker); ok {
+			if start, err = s.Seek(0, io.SeekCurrent); err != nil {
+				return
+			}
+			seeker = s
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		err = req.send()
+		if err == nil || attempt >= req.client.MaxRetries || !req.retryable(err, attempt+1) {
+			return
+		}
+		if req.reqBody != nil {
+			if seeker == nil {
+				return
+			}
+			if _, serr := seeker.Seek(start, io.SeekStart); serr != nil {
+				return
+			}
+		}
+		if req.reqCrc64 != nil {
+			req.reqCrc64.Reset()
+		}
+		if req.respCrc64 != nil {
+			req.respCrc64.Reset()
+		}
+		req.client.logf("retrying %s %s: %v", req.method, req.URL(), err)
+		delay := retryDelay(attempt)
+		if req.Response != nil {
+			if d, ok := parseRetryAfter(req.Response.Header.Get("Retry-After"), req.client.now()); ok {
+				delay = d
+			}
+		}
+		if serr := sleepContext(req.ctx, delay); serr != nil {
+			return
+		}
+	}
+}
+
+// retryable returns false if the context is done, otherwise it returns the
+// result of ShouldRetry of the client or DefaultShouldRetry.
+func (req *Request) retryable(err error, attempt int) bool {
+	if req.ctx.Err() != nil {
+		return false
+	}
+	shouldRetry := req.client.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = DefaultShouldRetry
+	}
+	return shouldRetry(req.Response, err, attempt)
+}
+
+// DefaultShouldRetry returns true if the request failed before a response
+// was received (like a network error) or OSS responded with a 5xx status
+// code.
+func DefaultShouldRetry(resp *http.Response, err error, attempt int) bool {
+	if resp == nil {
+		return err != nil
+	}
+	return resp.StatusCode >= 500
+}
+
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 0; i < attempt && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// parseRetryAfter parses Retry-After header, which is either seconds or an
+// HTTP date, returns the duration to wait from now, up to retryMaxDelay.
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	var d time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > int(retryMaxDelay/time.Second) {
+			return retryMaxDelay, true
+		}
+		d = time.Duration(seconds) * time.Second
+	} else {
+		t, err := http.ParseTime(header)
+		if err != nil {
+			return 0, false
+		}
+		if d = t.Sub(now); d < 0 {
+			d = 0
+		}
+	}
+	if d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	return d, true
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
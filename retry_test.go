@@ -0,0 +1,137 @@
+package ossslim
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type readerOnly struct {
+	io.Reader
+}
+
+func TestRetry(t *testing.T) {
+	var attempts int
+	var bodies []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if attempts < 3 {
+			w.WriteHeader(503)
+			return
+		}
+	})
+	client.MaxRetries = 2
+	req, err := client.Upload("foo", strings.NewReader("hello"), nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 || strings.Join(bodies, ",") != "hello,hello,hello" {
+		t.Error("wrong attempts", attempts, bodies)
+	}
+	if req.BytesSent != 5 {
+		t.Error("wrong bytes sent", req.BytesSent)
+	}
+
+	attempts = 0
+	_, err = client.Upload("foo", readerOnly{strings.NewReader("hello")}, nil, "")
+	if err == nil || attempts != 1 {
+		t.Error("non-seekable body should not be retried", attempts, err)
+	}
+}
+
+func TestSpoolBody(t *testing.T) {
+	content := strings.Repeat("0123456789", 10)
+	for _, limit := range []int64{1000, 10} {
+		var attempts int
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			body, _ := ioutil.ReadAll(r.Body)
+			if string(body) != content {
+				t.Error("wrong body", string(body))
+			}
+			if r.ContentLength != int64(len(content)) {
+				t.Error("wrong content length", r.ContentLength)
+			}
+			if attempts == 1 {
+				w.WriteHeader(500)
+			}
+		})
+		client.MaxRetries = 1
+		_, err := client.Upload("foo", readerOnly{strings.NewReader(content)}, nil, "", SpoolBody(limit))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attempts != 2 {
+			t.Error("wrong attempts", attempts)
+		}
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	var attempts int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(403)
+		w.Write([]byte(`<Error><Code>RequestTimeTooSkewed</Code><Message>skewed</Message></Error>`))
+	})
+	client.MaxRetries = 5
+	var got []int
+	client.ShouldRetry = func(resp *http.Response, err error, attempt int) bool {
+		got = append(got, attempt)
+		var ossErr *Error
+		return resp.StatusCode == 403 && errors.As(err, &ossErr) && ossErr.Code == "RequestTimeTooSkewed" && attempt < 2
+	}
+	_, err := client.Upload("foo", strings.NewReader("hello"), nil, "")
+	if err == nil {
+		t.Fatal("should fail")
+	}
+	if attempts != 2 || len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Error("wrong attempts", attempts, got)
+	}
+	if DefaultShouldRetry(nil, errors.New("network"), 1) != true || DefaultShouldRetry(&http.Response{StatusCode: 404}, nil, 1) != false {
+		t.Error("wrong default")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var attempts int
+	var first time.Time
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(503)
+			return
+		}
+		if d := time.Since(first); d < time.Second {
+			t.Error("Retry-After is not honored", d)
+		}
+	})
+	client.MaxRetries = 1
+	if _, err := client.Download("foo", ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for header, expected := range map[string]time.Duration{
+		"5":                             5 * time.Second,
+		"Wed, 01 Jan 2020 00:00:05 GMT": 5 * time.Second,
+		"Tue, 31 Dec 2019 00:00:00 GMT": 0,
+		"120":                           retryMaxDelay,
+		"99999999999999999":             retryMaxDelay,
+		"Thu, 01 Jan 2099 00:00:00 GMT": retryMaxDelay,
+	} {
+		if d, ok := parseRetryAfter(header, now); !ok || d != expected {
+			t.Error("wrong duration for", header, d)
+		}
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Error("invalid header should be ignored")
+	}
+}
@@ -0,0 +1,130 @@
+package ossslim
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	v4Algorithm   = "OSS4-HMAC-SHA256"
+	v4Request     = "aliyun_v4_request"
+	v4DateFormat  = "20060102T150405Z"
+	v4UnsignedPay = "UNSIGNED-PAYLOAD"
+)
+
+// region returns Region of the client or, if it is empty, the region in the
+// host name of Endpoint or Prefix, for example cn-hangzhou for
+// https://<bucket>.oss-cn-hangzhou.aliyuncs.com. Transfer acceleration
+// endpoints have no region, the region is taken from Prefix then.
+func (c *Client) region() string {
+	if c.Region != "" {
+		return strings.TrimPrefix(c.Region, "oss-")
+	}
+	for _, host := range []string{c.Endpoint, c.Prefix} {
+		u, err := url.Parse(host)
+		if err != nil {
+			continue
+		}
+		for _, label := range strings.Split(u.Hostname(), ".") {
+			if strings.HasPrefix(label, "oss-") && !strings.HasPrefix(label, "oss-accelerate") {
+				return strings.TrimSuffix(strings.TrimPrefix(label, "oss-"), "-internal")
+			}
+		}
+	}
+	return ""
+}
+
+// setV4Headers sets headers required by signature version 4, must be called
+// before the headers are sent and signed.
+func (req *Request) setV4Headers() {
+	req.setHeader("x-oss-date", req.client.now().Format(v4DateFormat))
+	req.setHeader("x-oss-content-sha256", v4UnsignedPay)
+}
+
+func (req *Request) authorizationV4() string {
+	date := req.header.Get("x-oss-date")
+	scope := strings.Join([]string{date[:8], req.client.region(), "oss", v4Request}, "/")
+	stringToSign := strings.Join([]string{
+		v4Algorithm,
+		date,
+		scope,
+		sha256Hex(req.canonicalRequestV4()),
+	}, "\n")
+	req.StringToSign = stringToSign
+	key := hmacSha256([]byte("aliyun_v4"+req.credentials().AccessKeySecret), date[:8])
+	key = hmacSha256(key, req.client.region())
+	key = hmacSha256(key, "oss")
+	key = hmacSha256(key, v4Request)
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+	return v4Algorithm + " Credential=" + req.credentials().AccessKeyId + "/" + scope + ",Signature=" + signature
+}
+
+func (req *Request) canonicalRequestV4() string {
+	uri := req.resourcePath()
+	var queries []string
+	for key, values := range req.queries {
+		for _, value := range values {
+			if value == "" {
+				queries = append(queries, v4Escape(key))
+			} else {
+				queries = append(queries, v4Escape(key)+"="+v4Escape(value))
+			}
+		}
+	}
+	sort.Strings(queries)
+	headers := map[string]string{}
+	for key := range req.header {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "x-oss-") {
+			headers[lower] = strings.TrimSpace(req.header.Get(key))
+		}
+	}
+	if req.contentType != "" {
+		headers["content-type"] = req.contentType
+	}
+	if req.contentMd5 != "" {
+		headers["content-md5"] = req.contentMd5
+	}
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var canonicalHeaders strings.Builder
+	for _, key := range keys {
+		canonicalHeaders.WriteString(key + ":" + headers[key] + "\n")
+	}
+	return strings.Join([]string{
+		req.method,
+		strings.ReplaceAll(v4Escape(uri), "%2F", "/"),
+		strings.Join(queries, "&"),
+		canonicalHeaders.String(),
+		"", // no additional headers
+		v4UnsignedPay,
+	}, "\n")
+}
+
+// v4Escape escapes all characters except A-Z, a-z, 0-9, "-", "_", "." and
+// "~".
+func v4Escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func (req *Request) useV4() bool {
+	return req.client.SignatureVersion == 4 && req.client.Flavor != FlavorS3
+}
@@ -0,0 +1,68 @@
+package ossslim
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// SpoolBody reads the request body entirely before it is sent, so the body
+// can be rewound and resent when the request is retried (see MaxRetries) or
+// its MD5 can be computed with the AutoMD5 option. Bodies up to memoryLimit
+// bytes are kept in memory, larger bodies are written to a temporary file
+// which is removed after the upload. Use a small memoryLimit to save memory
+// when uploading large streams, at the cost of writing them to disk. Bodies
+// that are already io.Seeker are not spooled.
+func SpoolBody(memoryLimit int64) Option {
+	return func(req *Request) {
+		if memoryLimit < 1 {
+			memoryLimit = 1
+		}
+		req.spoolLimit = memoryLimit
+	}
+}
+
+// spoolBody replaces the request body with a seekable copy, the returned
+// function removes the temporary file if any.
+func (req *Request) spoolBody() (cleanup func(), err error) {
+	cleanup = func() {}
+	if req.reqBody == nil {
+		return
+	}
+	if _, ok := req.reqBody.(io.Seeker); ok {
+		return
+	}
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, req.reqBody, req.spoolLimit+1)
+	if err == io.EOF {
+		req.reqBody = bytes.NewReader(buf.Bytes())
+		req.reqBodyLength = n
+		return cleanup, nil
+	}
+	if err != nil {
+		return
+	}
+	file, err := ioutil.TempFile("", "ossslim-")
+	if err != nil {
+		return
+	}
+	cleanup = func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+	if _, err = buf.WriteTo(file); err == nil {
+		var m int64
+		if m, err = io.Copy(file, req.reqBody); err == nil {
+			n += m
+			_, err = file.Seek(0, io.SeekStart)
+		}
+	}
+	if err != nil {
+		cleanup()
+		return func() {}, err
+	}
+	req.reqBody = file
+	req.reqBodyLength = n
+	return
+}
@@ -0,0 +1,49 @@
+package ossslim
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// CreateSymlink wraps CreateSymlinkWithContext using context.Background.
+func (c *Client) CreateSymlink(remote, target string) error {
+	return c.CreateSymlinkWithContext(context.Background(), remote, target)
+}
+
+// CreateSymlinkWithContext creates a symlink at remote path pointing to
+// target, which is another remote path in the same bucket. Downloading the
+// symlink returns the content of the target file.
+func (c *Client) CreateSymlinkWithContext(ctx context.Context, remote, target string) error {
+	req := &Request{
+		client:  c,
+		ctx:     ctx,
+		remote:  remote,
+		method:  "PUT",
+		queries: url.Values{"symlink": []string{""}},
+	}
+	req.setHeader("x-oss-symlink-target", url.QueryEscape(strings.TrimPrefix(target, "/")))
+	return req.do()
+}
+
+// GetSymlinkTarget wraps GetSymlinkTargetWithContext using
+// context.Background.
+func (c *Client) GetSymlinkTarget(remote string) (string, error) {
+	return c.GetSymlinkTargetWithContext(context.Background(), remote)
+}
+
+// GetSymlinkTargetWithContext returns the target of the symlink at remote
+// path.
+func (c *Client) GetSymlinkTargetWithContext(ctx context.Context, remote string) (string, error) {
+	req := &Request{
+		client:  c,
+		ctx:     ctx,
+		remote:  remote,
+		method:  "GET",
+		queries: url.Values{"symlink": []string{""}},
+	}
+	if err := req.do(); err != nil {
+		return "", err
+	}
+	return url.QueryUnescape(req.Response.Header.Get("x-oss-symlink-target"))
+}
@@ -0,0 +1,105 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/url"
+	"strings"
+)
+
+type (
+	// Version is a version of a file in a bucket with versioning enabled.
+	// If IsDeleteMarker is true, the version is a delete marker and it has
+	// no content.
+	Version struct {
+		Name           string `xml:"Key"`
+		VersionId      string
+		IsLatest       bool
+		IsDeleteMarker bool `xml:"-"`
+		LastModified   string
+		ETag           string
+		Size           int64
+		StorageClass   string
+		Owner          Owner
+	}
+
+	listVersionsResult struct {
+		NextKeyMarker       string
+		NextVersionIdMarker string
+		IsTruncated         bool
+		Versions            []Version `xml:"Version"`
+		DeleteMarkers       []Version `xml:"DeleteMarker"`
+	}
+)
+
+// WithVersionId makes the request access a specific version of a file in a
+// bucket with versioning enabled. It can be used in Download, Stat and other
+// methods accepting options.
+func WithVersionId(versionId string) Option {
+	return func(req *Request) {
+		req.setQuery("versionId", versionId)
+	}
+}
+
+// ListVersions wraps ListVersionsWithContext using context.Background.
+func (c *Client) ListVersions(prefix string) ([]Version, error) {
+	return c.ListVersionsWithContext(context.Background(), prefix)
+}
+
+// ListVersionsWithContext lists all versions and delete markers of files
+// whose keys start with prefix.
+func (c *Client) ListVersionsWithContext(ctx context.Context, prefix string) (versions []Version, err error) {
+	var keyMarker, versionIdMarker string
+	for {
+		var response bytes.Buffer
+		req := &Request{
+			client:   c,
+			ctx:      ctx,
+			remote:   "/",
+			method:   "GET",
+			respBody: &response,
+			queries: url.Values{
+				"versions":          []string{""},
+				"max-keys":          []string{"1000"},
+				"prefix":            []string{strings.TrimPrefix(prefix, "/")},
+				"key-marker":        []string{keyMarker},
+				"version-id-marker": []string{versionIdMarker},
+			},
+		}
+		if err = req.do(); err != nil {
+			return
+		}
+		var result listVersionsResult
+		if err = xml.NewDecoder(&response).Decode(&result); err != nil {
+			return
+		}
+		versions = append(versions, result.Versions...)
+		for _, marker := range result.DeleteMarkers {
+			marker.IsDeleteMarker = true
+			versions = append(versions, marker)
+		}
+		if !result.IsTruncated {
+			return
+		}
+		keyMarker, versionIdMarker = result.NextKeyMarker, result.NextVersionIdMarker
+	}
+}
+
+// DeleteVersion wraps DeleteVersionWithContext using context.Background.
+func (c *Client) DeleteVersion(remote, versionId string) error {
+	return c.DeleteVersionWithContext(context.Background(), remote, versionId)
+}
+
+// DeleteVersionWithContext permanently deletes a specific version (or delete
+// marker) of remote file.
+func (c *Client) DeleteVersionWithContext(ctx context.Context, remote, versionId string) error {
+	req := &Request{
+		client:  c,
+		ctx:     ctx,
+		remote:  remote,
+		method:  "DELETE",
+		queries: url.Values{"versionId": []string{versionId}},
+	}
+	return req.do()
+}
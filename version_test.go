@@ -0,0 +1,72 @@
+package ossslim
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestVersions(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.Method == "GET" && q["versions"] != nil:
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListVersionsResult>
+  <Name>test</Name>
+  <IsTruncated>false</IsTruncated>
+  <DeleteMarker>
+    <Key>foo</Key>
+    <VersionId>CAEQMxiBgICAof2D0BYiIDJhMGE3N2M1YTI1NDQzOGY5NTkyNTI3MGYyMzJm****</VersionId>
+    <IsLatest>true</IsLatest>
+    <LastModified>2019-04-09T07:27:28.000Z</LastModified>
+  </DeleteMarker>
+  <Version>
+    <Key>foo</Key>
+    <VersionId>CAEQMxiBgMDNoP2D0BYiIDE3MWUxNzgxZDQxNTRiODI5OGYwZGMwNGY3MzZj****</VersionId>
+    <IsLatest>false</IsLatest>
+    <LastModified>2019-04-09T07:27:28.000Z</LastModified>
+    <ETag>"250F8A0AE989679A22926A875F0A2****"</ETag>
+    <Size>5</Size>
+    <StorageClass>Standard</StorageClass>
+  </Version>
+</ListVersionsResult>`))
+		case r.Method == "GET":
+			w.Write([]byte(q.Get("versionId")))
+		case r.Method == "HEAD":
+			w.Header().Set("x-oss-version-id", q.Get("versionId"))
+			w.Header().Set("Last-Modified", "Tue, 09 Apr 2019 07:27:28 GMT")
+		case r.Method == "DELETE":
+			if q.Get("versionId") == "" {
+				t.Error("no version id")
+			}
+			w.WriteHeader(204)
+		}
+	})
+	versions, err := client.ListVersions("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].IsDeleteMarker || versions[0].Size != 5 ||
+		!versions[1].IsDeleteMarker || !versions[1].IsLatest {
+		t.Fatal("wrong versions", versions)
+	}
+	versionId := versions[0].VersionId
+	var buf bytes.Buffer
+	if _, err := client.Download("foo", &buf, WithVersionId(versionId)); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != versionId {
+		t.Fatal("wrong version downloaded", buf.String())
+	}
+	info, _, err := client.Stat("foo", WithVersionId(versionId))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.VersionId != versionId || info.LastModified.Year() != 2019 {
+		t.Fatal("wrong info", info)
+	}
+	if err := client.DeleteVersion("foo", versionId); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,152 @@
+package ossslim
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"net/url"
+)
+
+type (
+	// Worm is the retention policy (write once read many) of a bucket. State
+	// is "InProgress" after the policy is initiated, and becomes "Locked"
+	// after it is completed, files in the bucket can not be deleted or
+	// modified until RetentionPeriodInDays days after they are uploaded.
+	Worm struct {
+		WormId                string
+		State                 string
+		RetentionPeriodInDays int
+		CreationDate          string
+	}
+
+	wormConfiguration struct {
+		XMLName               xml.Name
+		RetentionPeriodInDays int
+	}
+)
+
+// InitiateBucketWorm wraps InitiateBucketWormWithContext using
+// context.Background.
+func (c *Client) InitiateBucketWorm(days int) (string, error) {
+	return c.InitiateBucketWormWithContext(context.Background(), days)
+}
+
+// InitiateBucketWormWithContext creates a retention policy of days days for
+// the bucket and returns its ID. The policy must be completed with
+// CompleteBucketWorm within 24 hours, otherwise it is removed.
+func (c *Client) InitiateBucketWormWithContext(ctx context.Context, days int) (string, error) {
+	req, err := c.wormRequest(ctx, url.Values{"worm": []string{""}}, "InitiateWormConfiguration", days)
+	if err != nil {
+		return "", err
+	}
+	if err := req.do(); err != nil {
+		return "", err
+	}
+	return req.Response.Header.Get("x-oss-worm-id"), nil
+}
+
+// CompleteBucketWorm wraps CompleteBucketWormWithContext using
+// context.Background.
+func (c *Client) CompleteBucketWorm(wormId string) error {
+	return c.CompleteBucketWormWithContext(context.Background(), wormId)
+}
+
+// CompleteBucketWormWithContext locks the retention policy, after which the
+// policy can not be removed and its retention period can only be extended.
+func (c *Client) CompleteBucketWormWithContext(ctx context.Context, wormId string) error {
+	req := &Request{
+		client:  c,
+		ctx:     ctx,
+		remote:  "/",
+		method:  "POST",
+		queries: url.Values{"wormId": []string{wormId}},
+	}
+	return req.do()
+}
+
+// ExtendBucketWorm wraps ExtendBucketWormWithContext using
+// context.Background.
+func (c *Client) ExtendBucketWorm(wormId string, days int) error {
+	return c.ExtendBucketWormWithContext(context.Background(), wormId, days)
+}
+
+// ExtendBucketWormWithContext changes the retention period of a locked
+// policy to days days, which must be longer than the current period.
+func (c *Client) ExtendBucketWormWithContext(ctx context.Context, wormId string, days int) error {
+	req, err := c.wormRequest(ctx, url.Values{
+		"wormId":     []string{wormId},
+		"wormExtend": []string{""},
+	}, "ExtendWormConfiguration", days)
+	if err != nil {
+		return err
+	}
+	return req.do()
+}
+
+// AbortBucketWorm wraps AbortBucketWormWithContext using context.Background.
+func (c *Client) AbortBucketWorm() error {
+	return c.AbortBucketWormWithContext(context.Background())
+}
+
+// AbortBucketWormWithContext removes the retention policy which has not been
+// completed.
+func (c *Client) AbortBucketWormWithContext(ctx context.Context) error {
+	req := &Request{
+		client:  c,
+		ctx:     ctx,
+		remote:  "/",
+		method:  "DELETE",
+		queries: url.Values{"worm": []string{""}},
+	}
+	return req.do()
+}
+
+// GetBucketWorm wraps GetBucketWormWithContext using context.Background.
+func (c *Client) GetBucketWorm() (*Worm, error) {
+	return c.GetBucketWormWithContext(context.Background())
+}
+
+// GetBucketWormWithContext returns the retention policy of the bucket. An
+// *Error with code NoSuchWORMConfiguration is returned if there is none.
+func (c *Client) GetBucketWormWithContext(ctx context.Context) (*Worm, error) {
+	var response bytes.Buffer
+	req := &Request{
+		client:   c,
+		ctx:      ctx,
+		remote:   "/",
+		method:   "GET",
+		respBody: &response,
+		queries:  url.Values{"worm": []string{""}},
+	}
+	if err := req.do(); err != nil {
+		return nil, err
+	}
+	var worm Worm
+	if err := xml.NewDecoder(&response).Decode(&worm); err != nil {
+		return nil, err
+	}
+	return &worm, nil
+}
+
+func (c *Client) wormRequest(ctx context.Context, queries url.Values, name string, days int) (*Request, error) {
+	var reqBody bytes.Buffer
+	reqBody.WriteString(xml.Header)
+	if err := xml.NewEncoder(&reqBody).Encode(wormConfiguration{
+		XMLName:               xml.Name{Local: name},
+		RetentionPeriodInDays: days,
+	}); err != nil {
+		return nil, err
+	}
+	md5sum := md5.Sum(reqBody.Bytes())
+	return &Request{
+		client:     c,
+		ctx:        ctx,
+		remote:     "/",
+		method:     "POST",
+		reqBody:    bytes.NewReader(reqBody.Bytes()),
+		contentMd5: base64.StdEncoding.EncodeToString(md5sum[:]),
+		queries:    queries,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package ossslim
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBucketWorm(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.RawQuery+" "+strings.TrimPrefix(string(body), `<?xml version="1.0" encoding="UTF-8"?>`+"\n"))
+		switch r.Method {
+		case "POST":
+			w.Header().Set("x-oss-worm-id", "1666E2CFB2B3418****")
+		case "GET":
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<WormConfiguration>
+  <WormId>1666E2CFB2B3418****</WormId>
+  <State>Locked</State>
+  <RetentionPeriodInDays>1</RetentionPeriodInDays>
+  <CreationDate>2020-10-15T15:50:32</CreationDate>
+</WormConfiguration>`))
+		}
+	})
+	wormId, err := client.InitiateBucketWorm(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wormId != "1666E2CFB2B3418****" {
+		t.Error("wrong worm id", wormId)
+	}
+	if err := client.CompleteBucketWorm(wormId); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ExtendBucketWorm(wormId, 2); err != nil {
+		t.Fatal(err)
+	}
+	worm, err := client.GetBucketWorm()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worm.WormId != wormId || worm.State != "Locked" || worm.RetentionPeriodInDays != 1 {
+		t.Errorf("wrong worm %+v", worm)
+	}
+	if err := client.AbortBucketWorm(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"POST worm <InitiateWormConfiguration><RetentionPeriodInDays>1</RetentionPeriodInDays></InitiateWormConfiguration>",
+		"POST wormId=1666E2CFB2B3418%2A%2A%2A%2A ",
+		"POST wormExtend&wormId=1666E2CFB2B3418%2A%2A%2A%2A <ExtendWormConfiguration><RetentionPeriodInDays>2</RetentionPeriodInDays></ExtendWormConfiguration>",
+		"GET worm ",
+		"DELETE worm ",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Error("wrong requests", strings.Join(requests, "\n"))
+	}
+}